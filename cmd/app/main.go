@@ -4,12 +4,19 @@ import (
 	"L0/internal/app"
 	"L0/internal/cache"
 	"L0/internal/config"
+	"L0/internal/health"
 	"L0/internal/kafka"
+	"L0/internal/kafka/dto"
+	"L0/internal/metrics"
 	"L0/internal/repository"
 	"L0/internal/repository/postgres"
 	"L0/internal/service"
+	"L0/internal/source"
+	natssource "L0/internal/source/nats"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -18,16 +25,32 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	gocache "github.com/patrickmn/go-cache"
+	"github.com/redis/go-redis/v9"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
+// Режимы прогрева кэша (cache.warmup.mode)
+const (
+	warmupFull = "full"
+	warmupNone = "none"
+)
+
+// sourceSetupTimeout ограничивает подключение к источнику сообщений при старте
+const sourceSetupTimeout = 10 * time.Second
+
 func main() {
 	cfg := config.MustLoad()
 	log := setupLogger(cfg.Env)
 	log.Info("Starting service", slog.String("env", cfg.Env))
 
-	cacheImpl := cache.New(5*time.Minute, 10*time.Minute)
+	cacheImpl, err := setupCache(cfg.Cache, log)
+	if err != nil {
+		log.Error("Failed to init cache", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if stats, ok := cacheImpl.(cache.StatsProvider); ok {
+		metrics.RegisterCache(stats)
+	}
 
 	storageImpl, err := postgres.New(
 		cfg.DBHost,
@@ -40,25 +63,63 @@ func main() {
 		log.Error("Failed init repository", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
+	instanceID := newInstanceID()
+	if cfg.Cache.Invalidation.Enabled {
+		storageImpl.Notifier = postgres.NewNotifier(cfg.Cache.Invalidation.Channel, instanceID)
+	}
+	// Событие о сохранении пишется в outbox в той же транзакции, что и заказ
+	storageImpl.WriteOutbox = cfg.Kafka.Outbox.Enabled
 	var repo repository.Repository = storageImpl
 
-	if err := loadCacheFromDB(storageImpl, cacheImpl); err != nil {
-		log.Error("Failed to load cache from DB", slog.String("error", err.Error()))
+	orderService := service.NewOrderService(repo, cacheImpl, log,
+		service.WithNegativeCache(cfg.Cache.NegativeTTL, cfg.Cache.NegativeMaxEntries),
+		service.WithRefreshAhead(refreshWindow(cfg.Cache.RefreshAhead)))
+
+	if len(cfg.Kafka.Brokers) == 0 && (cfg.Source.Type == source.TypeKafka || cfg.Kafka.DLQTopic != "" || cfg.Kafka.Outbox.Enabled) {
+		log.Error("kafka.brokers is required for the Kafka source, DLQ and outbox")
 		os.Exit(1)
 	}
 
-	orderService := service.NewOrderService(repo, cacheImpl, log)
+	var dlq kafka.DeadLetterPublisher
+	if cfg.Kafka.DLQTopic != "" {
+		dlq = kafka.NewDeadLetterPublisher(cfg.Kafka.Brokers, cfg.Kafka.DLQTopic)
+		log.Info("DLQ enabled", slog.String("topic", cfg.Kafka.DLQTopic))
+	}
 
-	kafkaConsumer := kafka.NewOrderConsumer(log)
+	kafkaConsumer := kafka.NewOrderConsumer(log, dlq, kafka.NewRetryPolicy(cfg.Kafka.Retry), kafka.ConsumerOptions{
+		Workers:      cfg.Kafka.Workers,
+		BatchSize:    cfg.Kafka.BatchSize,
+		BatchTimeout: cfg.Kafka.BatchTimeout,
+	})
+	orderSource, statusSource, err := openSources(cfg)
+	if err != nil {
+		log.Error("Failed to open message source", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	log.Info("Message source opened",
+		slog.String("type", cfg.Source.Type),
+		slog.Bool("status_events", statusSource != nil))
 
 	r := chi.NewRouter()
+	r.Use(metrics.HTTPMiddleware)
+	r.Handle("/metrics", metrics.Handler())
 	r.Handle("/docs/*", http.StripPrefix("/docs/", http.FileServer(http.Dir("./docs/"))))
 	r.Get("/swagger/*", httpSwagger.Handler(httpSwagger.URL("/docs/swagger.yaml")))
 	app.RegisterRoutes(r, orderService, log)
 
-	server := &http.Server{
-		Addr:    ":8080",
-		Handler: r,
+	cacheReady := health.NewFlag("cache warm-up in progress")
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+	checker.Register("cache", cacheReady.Check)
+	checker.Register("postgres", storageImpl.Ping)
+	if cfg.Source.Type == source.TypeKafka {
+		checker.Register("kafka", health.Freshness(kafkaConsumer.LastFetch, cfg.Health.KafkaFetchWindow))
+	}
+	app.RegisterHealthRoutes(r, checker, log)
+
+	server, certReloader, err := app.NewHTTPServer(cfg.HTTPServer, r)
+	if err != nil {
+		log.Error("Failed to configure HTTP server", slog.String("error", err.Error()))
+		os.Exit(1)
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -66,19 +127,116 @@ func main() {
 
 	errCh := make(chan error, 1)
 
+	if certReloader != nil {
+		go certReloader.WatchSignals(ctx, log)
+	}
+
 	go func() {
-		log.Info("HTTP server started on :8080")
-		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Info("HTTP server started",
+			slog.String("address", server.Addr),
+			slog.Bool("tls", server.TLSConfig != nil))
+		if err := app.Serve(server); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errCh <- err
 		}
 	}()
 
+	// /readyz не готов, пока кэш прогревается в фоне
+	warmer := service.NewCacheWarmer(repo, cacheImpl, log, service.WarmupOptions{
+		BatchSize: cfg.Cache.Warmup.BatchSize,
+		MaxOrders: cfg.Cache.Warmup.MaxOrders,
+		Window:    cfg.Cache.Warmup.Window,
+		TTL:       cfg.Cache.Warmup.TTL,
+	})
+	var snapshotter *service.CacheSnapshotter
+	if cfg.Cache.Snapshot.Path != "" {
+		snapshotter = service.NewCacheSnapshotter(cacheImpl, warmer, log, service.SnapshotOptions{
+			Path:     cfg.Cache.Snapshot.Path,
+			Interval: cfg.Cache.Snapshot.Interval,
+			MaxAge:   cfg.Cache.Snapshot.MaxAge,
+			TTL:      cfg.Cache.Warmup.TTL,
+		})
+	}
+	cacheDone := make(chan struct{})
 	go func() {
-		if err := kafkaConsumer.ConsumeOrders(ctx, cfg.Kafka.Brokers, cfg.Kafka.Topic, cfg.Kafka.GroupID, repo); err != nil {
+		defer close(cacheDone)
+		if err := warmUpCache(ctx, cfg.Cache.Warmup.Mode, warmer, snapshotter, cacheReady, log); err != nil {
+			if ctx.Err() == nil {
+				errCh <- err
+			}
+			return
+		}
+		// Снимки пишутся только после прогрева, чтобы не сохранить неполный кэш
+		if snapshotter != nil {
+			snapshotter.Run(ctx)
+		}
+	}()
+
+	cacheAdmin := service.NewCacheAdmin(ctx, cacheImpl, warmer, log)
+
+	var adminServer *http.Server
+	if cfg.Admin.Address != "" {
+		adminServer, err = startAdminServer(ctx, cfg, app.NewCacheAdminRouter(cacheAdmin, cfg.Admin.Token, log), log, errCh)
+		if err != nil {
+			log.Error("Failed to configure admin server", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	if cfg.Cache.Invalidation.Enabled {
+		listener := postgres.NewListener(
+			postgres.DSN(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName),
+			cfg.Cache.Invalidation.Channel,
+			instanceID,
+			cfg.Cache.Invalidation.MinReconnect,
+			cfg.Cache.Invalidation.MaxReconnect,
+			log,
+		)
+		go func() {
+			if err := listener.Run(ctx, service.NewCacheInvalidator(cacheImpl, cacheAdmin, orderService, log)); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+
+	go func() {
+		if err := kafkaConsumer.ConsumeOrders(ctx, orderSource, orderService); err != nil {
 			errCh <- err
 		}
 	}()
 
+	if statusSource != nil {
+		go func() {
+			if err := kafkaConsumer.ConsumeStatusEvents(ctx, statusSource, orderService); err != nil {
+				errCh <- err
+			}
+		}()
+
+		sweeper := service.NewPendingStatusSweeper(storageImpl, orderService, log, service.PendingSweepOptions{
+			TTL:       cfg.Kafka.PendingStatus.TTL,
+			Interval:  cfg.Kafka.PendingStatus.Interval,
+			BatchSize: cfg.Kafka.PendingStatus.BatchSize,
+		})
+		go sweeper.Run(ctx)
+	}
+
+	var events kafka.EventPublisher
+	outboxDone := make(chan struct{})
+	if cfg.Kafka.Outbox.Enabled {
+		events = kafka.NewEventPublisher(cfg.Kafka.Brokers, cfg.Kafka.Outbox.Topic)
+		relay := service.NewOutboxRelay(storageImpl, events, log, service.OutboxOptions{
+			BatchSize:      cfg.Kafka.Outbox.BatchSize,
+			Interval:       cfg.Kafka.Outbox.Interval,
+			PublishTimeout: cfg.Kafka.Outbox.PublishTimeout,
+		})
+		go func() {
+			defer close(outboxDone)
+			relay.Run(ctx)
+		}()
+		log.Info("Outbox relay enabled", slog.String("topic", cfg.Kafka.Outbox.Topic))
+	} else {
+		close(outboxDone)
+	}
+
 	select {
 	case <-ctx.Done():
 		log.Info("Shutting down gracefully...")
@@ -96,6 +254,35 @@ func main() {
 		log.Info("HTTP server shutdown successfully")
 	}
 
+	if adminServer != nil {
+		if err := adminServer.Shutdown(shutdownCtx); err != nil {
+			log.Error("Failed to shutdown admin server", slog.String("error", err.Error()))
+		}
+	}
+
+	// Дожидаемся последнего снимка кэша
+	<-cacheDone
+
+	// Неопубликованные события останутся в outbox до следующего запуска
+	<-outboxDone
+	if events != nil {
+		if err := events.Close(); err != nil {
+			log.Error("Failed to close outbox writer", slog.String("error", err.Error()))
+		}
+	}
+
+	if dlq != nil {
+		if err := dlq.Close(); err != nil {
+			log.Error("Failed to close DLQ writer", slog.String("error", err.Error()))
+		}
+	}
+
+	if closer, ok := cacheImpl.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Error("Failed to close cache", slog.String("error", err.Error()))
+		}
+	}
+
 	if err := storageImpl.Close(); err != nil {
 		log.Error("Failed to close database connection", slog.String("error", err.Error()))
 	} else {
@@ -105,6 +292,132 @@ func main() {
 	log.Info("Service stopped")
 }
 
+// startAdminServer запускает API администрирования на отдельном адресе
+// с теми же таймаутами и TLS, что и основной сервер
+func startAdminServer(ctx context.Context, cfg *config.Config, handler http.Handler, log *slog.Logger, errCh chan<- error) (*http.Server, error) {
+	adminCfg := cfg.HTTPServer
+	adminCfg.Address = cfg.Admin.Address
+
+	server, certReloader, err := app.NewHTTPServer(adminCfg, handler)
+	if err != nil {
+		return nil, err
+	}
+	if certReloader != nil {
+		go certReloader.WatchSignals(ctx, log)
+	}
+	if cfg.Admin.Token == "" {
+		log.Warn("Admin API is not protected by a token", slog.String("address", server.Addr))
+	}
+
+	go func() {
+		log.Info("Admin server started", slog.String("address", server.Addr))
+		if err := app.Serve(server); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- fmt.Errorf("admin server: %w", err)
+		}
+	}()
+
+	return server, nil
+}
+
+// warmUpCache восстанавливает кэш из снимка и сверяет его с БД в фоне,
+// а если снимка нет или он непригоден - прогревает кэш из БД целиком.
+// В режиме none кэш сразу считается готовым и наполняется по запросам.
+func warmUpCache(ctx context.Context, mode string, warmer *service.CacheWarmer, snapshotter *service.CacheSnapshotter, ready *health.Flag, log *slog.Logger) error {
+	if mode == warmupNone {
+		log.Info("Cache warm-up is disabled")
+		ready.MarkReady()
+		return nil
+	}
+
+	if snapshotter != nil {
+		if takenAt, ok := snapshotter.Restore(); ok {
+			// Сверка не блокирует готовность: восстановленный кэш уже может отвечать
+			ready.MarkReady()
+			if _, err := snapshotter.Reconcile(ctx, takenAt); err != nil && ctx.Err() == nil {
+				log.Error("Failed to reconcile cache with database", slog.String("error", err.Error()))
+			}
+			return nil
+		}
+	}
+
+	if _, err := warmer.Run(ctx); err != nil {
+		return fmt.Errorf("failed to warm up cache: %w", err)
+	}
+	ready.MarkReady()
+	return nil
+}
+
+// openSources открывает источник заказов и, если он настроен, источник событий статусов
+func openSources(cfg *config.Config) (orders, statuses source.Reader, err error) {
+	switch cfg.Source.Type {
+	case source.TypeKafka:
+		orders = kafka.NewReader(cfg.Kafka.Brokers, cfg.Kafka.Topic, cfg.Kafka.GroupID)
+		if cfg.Kafka.StatusTopic != "" {
+			// Отдельная группа: ребаланс и лаг одного потока не затрагивают другой
+			statuses = kafka.NewReader(cfg.Kafka.Brokers, cfg.Kafka.StatusTopic, cfg.Kafka.GroupID+"_status")
+		}
+		return orders, statuses, nil
+
+	case source.TypeNATS:
+		ctx, cancel := context.WithTimeout(context.Background(), sourceSetupTimeout)
+		defer cancel()
+
+		nc := cfg.Source.NATS
+		opts := natssource.Options{URL: nc.URL, Stream: nc.Stream, Subject: nc.Subject, Durable: nc.Durable, AckWait: nc.AckWait, MaxAckPending: nc.MaxAckPending}
+		orders, err = natssource.NewReader(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		if nc.StatusSubject != "" {
+			opts.Subject, opts.Durable = nc.StatusSubject, nc.Durable+"_status"
+			statuses, err = natssource.NewReader(ctx, opts)
+			if err != nil {
+				_ = orders.Close()
+				return nil, nil, err
+			}
+		}
+		return orders, statuses, nil
+
+	case source.TypeFile:
+		fc := cfg.Source.File
+		orders, err = source.NewFileReader(fc.Path, "orders", fc.PollInterval)
+		if err != nil {
+			return nil, nil, err
+		}
+		if fc.StatusPath != "" {
+			statuses, err = source.NewFileReader(fc.StatusPath, "order_status_events", fc.PollInterval)
+			if err != nil {
+				_ = orders.Close()
+				return nil, nil, err
+			}
+		}
+		return orders, statuses, nil
+
+	case source.TypeStdin:
+		return source.NewStreamReader(os.Stdin, "stdin"), nil, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown source type %q", cfg.Source.Type)
+	}
+}
+
+// refreshWindow возвращает окно упреждающего обновления кэша, 0 - обновление выключено
+func refreshWindow(cfg config.RefreshAhead) time.Duration {
+	if !cfg.Enabled {
+		return 0
+	}
+	return cfg.Window
+}
+
+// newInstanceID возвращает идентификатор реплики для уведомлений об изменениях
+func newInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
 func setupLogger(env string) *slog.Logger {
 	switch env {
 	case "local":
@@ -118,13 +431,69 @@ func setupLogger(env string) *slog.Logger {
 	}
 }
 
-func loadCacheFromDB(storage *postgres.Storage, cache cache.Cache) error {
-	orders, err := postgres.GetAllOrders(context.Background(), storage.DB)
-	if err != nil {
-		return err
+func setupCache(cfg config.Cache, log *slog.Logger) (cache.Cache[string, *dto.OrderDTO], error) {
+	switch cfg.Warmup.Mode {
+	case warmupFull, warmupNone:
+	default:
+		return nil, fmt.Errorf("unknown cache warm-up mode %q", cfg.Warmup.Mode)
 	}
-	for _, order := range orders {
-		cache.Set(order.OrderUID, &order, gocache.DefaultExpiration)
+
+	log.Info("Cache settings",
+		slog.String("type", cfg.Type),
+		slog.Duration("ttl", cfg.TTL),
+		slog.String("warmup_mode", cfg.Warmup.Mode),
+		slog.Bool("refresh_ahead", cfg.RefreshAhead.Enabled))
+
+	switch cfg.Type {
+	case "", "gocache":
+		return cache.New[*dto.OrderDTO](cfg.TTL, cfg.CleanupInterval), nil
+	case "lru":
+		log.Info("Using LRU cache",
+			slog.Int("max_entries", cfg.MaxEntries),
+			slog.Int64("max_bytes", cfg.MaxBytes))
+		return cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{
+			MaxEntries:      cfg.MaxEntries,
+			MaxBytes:        cfg.MaxBytes,
+			DefaultTTL:      cfg.TTL,
+			CleanupInterval: cfg.CleanupInterval,
+			OnEvict: func(key string, _ *dto.OrderDTO) {
+				log.Debug("Order evicted from cache", slog.String("order_uid", key))
+			},
+		}), nil
+	case "redis":
+		log.Info("Using Redis cache",
+			slog.String("addr", cfg.Redis.Addr),
+			slog.String("prefix", cfg.Redis.Prefix))
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		// Недоступный Redis не мешает старту: чтения обслуживает L1, запросы уходят в БД
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Redis.Timeout)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			log.Warn("Redis is unavailable", slog.String("error", err.Error()))
+		}
+
+		opts := cache.RedisOptions[*dto.OrderDTO]{
+			Prefix:     cfg.Redis.Prefix,
+			DefaultTTL: cfg.TTL,
+			Timeout:    cfg.Redis.Timeout,
+			L1TTL:      cfg.Redis.L1TTL,
+			OnError: func(op string, err error) {
+				log.Warn("Redis cache error", slog.String("op", op), slog.String("error", err.Error()))
+			},
+		}
+		if !cfg.Redis.DisableL1 {
+			opts.L1 = cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{
+				MaxEntries: cfg.MaxEntries,
+				MaxBytes:   cfg.MaxBytes,
+				DefaultTTL: cfg.Redis.L1TTL,
+			})
+		}
+		return cache.NewRedis(client, opts), nil
+	default:
+		return nil, fmt.Errorf("unknown cache type %q", cfg.Type)
 	}
-	return nil
 }
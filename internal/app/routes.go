@@ -2,6 +2,7 @@ package app
 
 import (
 	"L0/internal/handlers"
+	"L0/internal/health"
 	"L0/internal/service"
 	"log/slog"
 
@@ -11,6 +12,30 @@ import (
 func RegisterRoutes(r *chi.Mux, orderService service.OrderService, logger *slog.Logger) {
 	orderHandler := handlers.NewOrderHandler(orderService, logger)
 	r.Route("/orders", func(r chi.Router) {
+		r.Get("/", orderHandler.List)
+		r.Post("/", orderHandler.Create)
 		r.Get("/{order_uid}", orderHandler.ServeHTTP)
+		r.Patch("/{order_uid}/status", orderHandler.UpdateStatus)
 	})
 }
+
+func RegisterHealthRoutes(r *chi.Mux, checker *health.Checker, logger *slog.Logger) {
+	healthHandler := handlers.NewHealthHandler(checker, logger)
+	r.Get("/healthz", healthHandler.Liveness)
+	r.Get("/readyz", healthHandler.Readiness)
+}
+
+// NewCacheAdminRouter собирает роутер API администрирования. Оно обслуживается отдельным
+// listener'ом и требует токен, если он задан.
+func NewCacheAdminRouter(cacheAdmin service.CacheAdmin, token string, logger *slog.Logger) *chi.Mux {
+	r := chi.NewRouter()
+	adminHandler := handlers.NewAdminHandler(cacheAdmin, logger)
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(handlers.RequireToken(token))
+		r.Get("/cache", adminHandler.CacheStats)
+		r.Delete("/cache", adminHandler.FlushCache)
+		r.Delete("/cache/{order_uid}", adminHandler.EvictOrder)
+		r.Post("/cache/rewarm", adminHandler.RewarmCache)
+	})
+	return r
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"L0/internal/config"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// NewHTTPServer собирает http.Server из конфига. Если TLS включён, вместе с сервером
+// возвращается CertReloader, который нужно подписать на SIGHUP.
+func NewHTTPServer(cfg config.HTTPServer, handler http.Handler) (*http.Server, *CertReloader, error) {
+	server := &http.Server{
+		Addr:              cfg.Address,
+		Handler:           handler,
+		ReadTimeout:       orDefault(cfg.ReadTimeout, cfg.Timeout),
+		WriteTimeout:      orDefault(cfg.WriteTimeout, cfg.Timeout),
+		ReadHeaderTimeout: orDefault(cfg.ReadHeaderTimeout, cfg.Timeout),
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+
+	if !cfg.TLS.Enabled {
+		return server, nil, nil
+	}
+
+	if cfg.TLS.CertFile == "" || cfg.TLS.KeyFile == "" {
+		return nil, nil, errors.New("tls.cert_file and tls.key_file are required when TLS is enabled")
+	}
+
+	minVersion, err := parseTLSVersion(cfg.TLS.MinVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reloader, err := NewCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	server.TLSConfig = &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	return server, reloader, nil
+}
+
+// Serve запускает сервер по HTTP или HTTPS в зависимости от TLSConfig
+func Serve(server *http.Server) error {
+	if server.TLSConfig != nil {
+		// Сертификат берётся из TLSConfig.GetCertificate
+		return server.ListenAndServeTLS("", "")
+	}
+	return server.ListenAndServe()
+}
+
+func orDefault(value, fallback time.Duration) time.Duration {
+	if value == 0 {
+		return fallback
+	}
+	return value
+}
@@ -1,29 +1,115 @@
 package cache
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/patrickmn/go-cache"
 )
 
-type GoCache struct {
+// GoCache - кэш в памяти на основе patrickmn/go-cache
+type GoCache[V any] struct {
 	c *cache.Cache
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// goCacheItem - значение вместе со временем записи
+type goCacheItem[V any] struct {
+	value    V
+	storedAt time.Time
 }
 
-func New(defaultTTL, cleanupInterval time.Duration) *GoCache {
-	return &GoCache{
+func New[V any](defaultTTL, cleanupInterval time.Duration) *GoCache[V] {
+	g := &GoCache[V]{
 		c: cache.New(defaultTTL, cleanupInterval),
 	}
+	// Вызывается как при истечении TTL, так и при явном удалении
+	g.c.OnEvicted(func(string, interface{}) {
+		g.evictions.Add(1)
+	})
+	return g
 }
 
-func (g *GoCache) Get(key string) (interface{}, bool) {
-	return g.c.Get(key)
+func (g *GoCache[V]) Get(key string) (V, bool) {
+	value, _, found := g.GetWithExpiration(key)
+	return value, found
+}
+
+func (g *GoCache[V]) GetWithExpiration(key string) (V, time.Time, bool) {
+	value, expiresAt, found := g.c.GetWithExpiration(key)
+	if !found {
+		g.misses.Add(1)
+		var zero V
+		return zero, time.Time{}, false
+	}
+	g.hits.Add(1)
+	return value.(goCacheItem[V]).value, expiresAt, true
 }
 
-func (g *GoCache) Set(key string, value interface{}, ttl time.Duration) {
-	g.c.Set(key, value, ttl)
+func (g *GoCache[V]) Set(key string, value V, ttl time.Duration) {
+	g.c.Set(key, goCacheItem[V]{value: value, storedAt: time.Now()}, ttl)
 }
 
-func (g *GoCache) Delete(key string) {
+func (g *GoCache[V]) Delete(key string) {
 	g.c.Delete(key)
 }
+
+func (g *GoCache[V]) GetOrLoad(key string, ttl time.Duration, load func() (V, error)) (V, error) {
+	return getOrLoad[string, V](g, key, ttl, load)
+}
+
+func (g *GoCache[V]) Len() int {
+	return g.c.ItemCount()
+}
+
+func (g *GoCache[V]) Keys() []string {
+	items := g.c.Items()
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (g *GoCache[V]) Range(fn func(key string, value V) bool) {
+	for key, item := range g.c.Items() {
+		if !fn(key, item.Object.(goCacheItem[V]).value) {
+			return
+		}
+	}
+}
+
+// Flush удаляет все записи; они учитываются в Evictions, как и при Delete
+func (g *GoCache[V]) Flush() {
+	n := g.c.ItemCount()
+	g.c.Flush()
+	g.evictions.Add(uint64(n))
+}
+
+// OldestEntry перебирает все записи, поэтому предназначен для администрирования, а не для горячего пути
+func (g *GoCache[V]) OldestEntry() (string, time.Time, bool) {
+	var (
+		oldestKey string
+		oldestAt  time.Time
+	)
+	for key, item := range g.c.Items() {
+		storedAt := item.Object.(goCacheItem[V]).storedAt
+		if oldestAt.IsZero() || storedAt.Before(oldestAt) {
+			oldestKey, oldestAt = key, storedAt
+		}
+	}
+	return oldestKey, oldestAt, !oldestAt.IsZero()
+}
+
+// Stats возвращает статистику кэша. Items включает ещё не вычищенные просроченные записи.
+func (g *GoCache[V]) Stats() Stats {
+	return Stats{
+		Items:     g.c.ItemCount(),
+		Hits:      g.hits.Load(),
+		Misses:    g.misses.Load(),
+		Evictions: g.evictions.Load(),
+	}
+}
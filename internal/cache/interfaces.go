@@ -1,9 +1,103 @@
 package cache
 
-import "time"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
-type Cache interface {
-	Get(key string) (interface{}, bool)
-	Set(key string, value interface{}, ttl time.Duration)
-	Delete(key string)
+// Специальные значения TTL, совпадают с константами patrickmn/go-cache
+const (
+	// DefaultExpiration - использовать TTL кэша по умолчанию
+	DefaultExpiration time.Duration = 0
+	// NoExpiration - запись не истекает
+	NoExpiration time.Duration = -1
+)
+
+// Cache - типизированный кэш: несовпадение типа значения обнаруживается при компиляции
+type Cache[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Set(key K, value V, ttl time.Duration)
+	Delete(key K)
+	// GetOrLoad возвращает значение из кэша, а при промахе вызывает load и кэширует результат.
+	// Ошибка load не кэшируется. Одновременные промахи не объединяются.
+	GetOrLoad(key K, ttl time.Duration, load func() (V, error)) (V, error)
+	// Len возвращает число записей; может включать ещё не вычищенные просроченные
+	Len() int
+	// Keys возвращает ключи непросроченных записей
+	Keys() []K
+	// Range обходит непросроченные записи, пока fn возвращает true.
+	// Обход идёт по снимку, поэтому fn может обращаться к кэшу.
+	Range(fn func(key K, value V) bool)
+	// Flush удаляет все записи
+	Flush()
+}
+
+// ExpirationProvider реализуется кэшами, которые сообщают время истечения записи
+type ExpirationProvider[K comparable, V any] interface {
+	// GetWithExpiration работает как Get и дополнительно возвращает момент истечения записи.
+	// Нулевое время означает, что запись не истекает.
+	GetWithExpiration(key K) (V, time.Time, bool)
+}
+
+// OldestEntryProvider реализуется кэшами, которые помнят время записи значений
+type OldestEntryProvider[K comparable] interface {
+	// OldestEntry возвращает ключ и время записи самой старой непросроченной записи
+	OldestEntry() (key K, storedAt time.Time, ok bool)
+}
+
+// getOrLoad - общая реализация GetOrLoad поверх Get и Set
+func getOrLoad[K comparable, V any](c Cache[K, V], key K, ttl time.Duration, load func() (V, error)) (V, error) {
+	if value, found := c.Get(key); found {
+		return value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+
+	c.Set(key, value, ttl)
+	return value, nil
+}
+
+// ItemsUnknown - значение Stats.Items кэша, который не может дёшево посчитать записи
+const ItemsUnknown = -1
+
+// ErrKeysTruncated означает, что перечисление ключей оборвалось и результат неполон
+var ErrKeysTruncated = errors.New("cache: key enumeration truncated")
+
+// KeyScanner реализуется кэшами, у которых перечисление ключей может оборваться, например по таймауту
+type KeyScanner[K comparable] interface {
+	// ScanKeys возвращает найденные ключи и ErrKeysTruncated, если перечисление неполное
+	ScanKeys(ctx context.Context) ([]K, error)
+}
+
+// Backfiller реализуется кэшами с ограниченной ёмкостью
+type Backfiller[K comparable, V any] interface {
+	// Backfill добавляет запись как давно использованную, если для неё есть место без вытеснения других записей
+	Backfill(key K, value V, ttl time.Duration) bool
+}
+
+// LocalFlusher реализуется общими кэшами с локальным уровнем перед общим хранилищем
+type LocalFlusher interface {
+	// FlushLocal очищает только локальный уровень, не трогая записи других реплик
+	FlushLocal()
+	// DeleteLocal удаляет ключ только из локального уровня
+	DeleteLocal(key string)
+}
+
+// Stats - счётчики работы кэша с момента создания
+type Stats struct {
+	// Items - число записей или ItemsUnknown
+	Items     int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// StatsProvider реализуется кэшами, которые ведут статистику
+type StatsProvider interface {
+	Stats() Stats
 }
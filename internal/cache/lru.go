@@ -0,0 +1,330 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRUOptions - ограничения и колбэки LRU-кэша. Нулевые лимиты означают отсутствие ограничения.
+type LRUOptions[K comparable, V any] struct {
+	MaxEntries int
+	MaxBytes   int64
+	DefaultTTL time.Duration
+	// CleanupInterval - как часто удалять просроченные записи, чтобы они не занимали место
+	// в лимитах; по умолчанию DefaultTTL. Очистка выполняется попутно с Set, Len и Stats.
+	CleanupInterval time.Duration
+	// Sizer оценивает размер значения в байтах, по умолчанию EstimateSize
+	Sizer func(value V) int64
+	// OnEvict вызывается для записей, вытесненных по лимиту или истёкших по TTL.
+	// Явное удаление через Delete колбэк не вызывает.
+	OnEvict func(key K, value V)
+}
+
+// LRU - кэш с вытеснением давно не использованных записей,
+// ограниченный количеством записей и оценочным объёмом в байтах
+type LRU[K comparable, V any] struct {
+	mu    sync.Mutex
+	opts  LRUOptions[K, V]
+	ll    *list.List // от недавно использованных к давно использованным
+	items map[K]*list.Element
+	bytes int64
+	// lastSweep - время последней очистки просроченных записей
+	lastSweep time.Time
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	size      int64
+	storedAt  time.Time
+	expiresAt time.Time // нулевое значение - без истечения
+}
+
+type evicted[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func NewLRU[K comparable, V any](opts LRUOptions[K, V]) *LRU[K, V] {
+	if opts.Sizer == nil {
+		opts.Sizer = func(value V) int64 { return EstimateSize(value) }
+	}
+	if opts.CleanupInterval <= 0 {
+		opts.CleanupInterval = opts.DefaultTTL
+	}
+	return &LRU[K, V]{
+		opts:      opts,
+		ll:        list.New(),
+		items:     make(map[K]*list.Element),
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	value, _, found := c.GetWithExpiration(key)
+	return value, found
+}
+
+func (c *LRU[K, V]) GetWithExpiration(key K) (V, time.Time, bool) {
+	var zero V
+	c.mu.Lock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		c.mu.Unlock()
+		return zero, time.Time{}, false
+	}
+
+	entry := el.Value.(*lruEntry[K, V])
+	if entry.expired(time.Now()) {
+		c.removeElement(el)
+		c.evictions++
+		c.misses++
+		c.mu.Unlock()
+		c.notify([]evicted[K, V]{{key: entry.key, value: entry.value}})
+		return zero, time.Time{}, false
+	}
+
+	c.ll.MoveToFront(el)
+	c.hits++
+	c.mu.Unlock()
+
+	return entry.value, entry.expiresAt, true
+}
+
+func (c *LRU[K, V]) Set(key K, value V, ttl time.Duration) {
+	entry := c.newEntry(key, value, ttl)
+
+	c.mu.Lock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+
+	// Запись, которая одна превышает бюджет, не кэшируем
+	if c.opts.MaxBytes > 0 && entry.size > c.opts.MaxBytes {
+		c.mu.Unlock()
+		return
+	}
+
+	// Просроченные записи уходят раньше, чем вытесняются живые
+	out := c.sweepExpired(entry.storedAt)
+
+	c.items[key] = c.ll.PushFront(entry)
+	c.bytes += entry.size
+
+	for c.overLimit() {
+		oldest := c.ll.Back()
+		victim := oldest.Value.(*lruEntry[K, V])
+		c.removeElement(oldest)
+		c.evictions++
+		out = append(out, evicted[K, V]{key: victim.key, value: victim.value})
+	}
+
+	c.mu.Unlock()
+	c.notify(out)
+}
+
+// Backfill добавляет запись в конец очереди вытеснения, если она помещается в лимиты целиком.
+// Прогрев загружает заказы от новых к старым: так самые свежие вытесняются последними,
+// а более старые заказы не вытесняют уже загруженные.
+func (c *LRU[K, V]) Backfill(key K, value V, ttl time.Duration) bool {
+	entry := c.newEntry(key, value, ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, bytes := c.ll.Len()+1, c.bytes+entry.size
+	el, exists := c.items[key]
+	if exists {
+		entries--
+		bytes -= el.Value.(*lruEntry[K, V]).size
+	}
+	if (c.opts.MaxEntries > 0 && entries > c.opts.MaxEntries) || (c.opts.MaxBytes > 0 && bytes > c.opts.MaxBytes) {
+		return false
+	}
+
+	if exists {
+		c.removeElement(el)
+	}
+	c.items[key] = c.ll.PushBack(entry)
+	c.bytes += entry.size
+	return true
+}
+
+func (c *LRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+func (c *LRU[K, V]) GetOrLoad(key K, ttl time.Duration, load func() (V, error)) (V, error) {
+	return getOrLoad[K, V](c, key, ttl, load)
+}
+
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	out := c.sweepExpired(time.Now())
+	n := c.ll.Len()
+	c.mu.Unlock()
+
+	c.notify(out)
+	return n
+}
+
+// Keys возвращает ключи от недавно использованных к давно использованным
+func (c *LRU[K, V]) Keys() []K {
+	entries := c.snapshot()
+	keys := make([]K, len(entries))
+	for i, entry := range entries {
+		keys[i] = entry.key
+	}
+	return keys
+}
+
+// Range обходит записи от недавно использованных к давно использованным, не меняя их порядок
+func (c *LRU[K, V]) Range(fn func(key K, value V) bool) {
+	for _, entry := range c.snapshot() {
+		if !fn(entry.key, entry.value) {
+			return
+		}
+	}
+}
+
+// Flush удаляет все записи без вызова OnEvict, как и Delete
+func (c *LRU[K, V]) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[K]*list.Element)
+	c.bytes = 0
+}
+
+// OldestEntry ищет запись с самым ранним временем записи. Порядок списка отражает
+// использование, а не запись, поэтому перебираются все записи.
+func (c *LRU[K, V]) OldestEntry() (K, time.Time, bool) {
+	var (
+		oldestKey K
+		oldestAt  time.Time
+	)
+	for _, entry := range c.snapshot() {
+		if oldestAt.IsZero() || entry.storedAt.Before(oldestAt) {
+			oldestKey, oldestAt = entry.key, entry.storedAt
+		}
+	}
+	return oldestKey, oldestAt, !oldestAt.IsZero()
+}
+
+// Stats возвращает статистику кэша
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.Lock()
+	out := c.sweepExpired(time.Now())
+	stats := Stats{
+		Items:     c.ll.Len(),
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+	c.mu.Unlock()
+
+	c.notify(out)
+	return stats
+}
+
+// Bytes возвращает оценочный объём записей в кэше
+func (c *LRU[K, V]) Bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bytes
+}
+
+// snapshot копирует непросроченные записи, чтобы обходить их без блокировки
+func (c *LRU[K, V]) snapshot() []*lruEntry[K, V] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entries := make([]*lruEntry[K, V], 0, c.ll.Len())
+	for el := c.ll.Front(); el != nil; el = el.Next() {
+		if entry := el.Value.(*lruEntry[K, V]); !entry.expired(now) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (c *LRU[K, V]) newEntry(key K, value V, ttl time.Duration) *lruEntry[K, V] {
+	entry := &lruEntry[K, V]{
+		key:      key,
+		value:    value,
+		size:     c.opts.Sizer(value) + keySize(key) + entryOverhead,
+		storedAt: time.Now(),
+	}
+	if ttl == DefaultExpiration {
+		ttl = c.opts.DefaultTTL
+	}
+	if ttl > 0 {
+		entry.expiresAt = entry.storedAt.Add(ttl)
+	}
+	return entry
+}
+
+// sweepExpired удаляет просроченные записи, если с прошлой очистки прошло CleanupInterval.
+// Вызывается под блокировкой; удалённые записи нужно передать в notify после её снятия.
+func (c *LRU[K, V]) sweepExpired(now time.Time) []evicted[K, V] {
+	if c.opts.CleanupInterval <= 0 || now.Sub(c.lastSweep) < c.opts.CleanupInterval {
+		return nil
+	}
+	c.lastSweep = now
+
+	var out []evicted[K, V]
+	for el := c.ll.Back(); el != nil; {
+		prev := el.Prev()
+		if entry := el.Value.(*lruEntry[K, V]); entry.expired(now) {
+			c.removeElement(el)
+			c.evictions++
+			out = append(out, evicted[K, V]{key: entry.key, value: entry.value})
+		}
+		el = prev
+	}
+	return out
+}
+
+func (c *LRU[K, V]) overLimit() bool {
+	if c.ll.Len() == 0 {
+		return false
+	}
+	return (c.opts.MaxEntries > 0 && c.ll.Len() > c.opts.MaxEntries) ||
+		(c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes)
+}
+
+func (c *LRU[K, V]) removeElement(el *list.Element) {
+	entry := el.Value.(*lruEntry[K, V])
+	c.ll.Remove(el)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+}
+
+// notify вызывает OnEvict вне блокировки, чтобы колбэк мог обращаться к кэшу
+func (c *LRU[K, V]) notify(out []evicted[K, V]) {
+	if c.opts.OnEvict == nil {
+		return
+	}
+	for _, e := range out {
+		c.opts.OnEvict(e.key, e.value)
+	}
+}
+
+func (e *lruEntry[K, V]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
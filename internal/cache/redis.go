@@ -0,0 +1,280 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultRedisTimeout = 500 * time.Millisecond
+	defaultL1TTL        = 30 * time.Second
+	redisScanCount      = 1000
+)
+
+// RedisOptions - параметры распределённого кэша
+type RedisOptions[V any] struct {
+	// Prefix добавляется ко всем ключам, чтобы несколько сервисов могли делить один Redis
+	Prefix     string
+	DefaultTTL time.Duration
+	// Timeout ограничивает каждую операцию с Redis, по умолчанию 500ms
+	Timeout time.Duration
+	// L1 - локальный кэш перед Redis. Используется для чтения без похода в сеть
+	// и как запасной вариант, пока Redis недоступен. nil - без локального уровня.
+	L1 Cache[string, V]
+	// L1TTL ограничивает время жизни записи в L1, чтобы реплики не расходились надолго
+	L1TTL time.Duration
+	// OnError вызывается при ошибках Redis: интерфейс Cache не возвращает ошибок
+	OnError func(op string, err error)
+}
+
+// Redis - кэш в Redis, общий для всех реплик сервиса. Значения хранятся в JSON.
+type Redis[V any] struct {
+	client redis.UniversalClient
+	opts   RedisOptions[V]
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+func NewRedis[V any](client redis.UniversalClient, opts RedisOptions[V]) *Redis[V] {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultRedisTimeout
+	}
+	if opts.L1TTL <= 0 {
+		opts.L1TTL = defaultL1TTL
+	}
+	return &Redis[V]{
+		client: client,
+		opts:   opts,
+	}
+}
+
+func (r *Redis[V]) Get(key string) (V, bool) {
+	if r.opts.L1 != nil {
+		if value, found := r.opts.L1.Get(key); found {
+			r.hits.Add(1)
+			return value, true
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.opts.Timeout)
+	defer cancel()
+
+	var zero V
+	data, err := r.client.Get(ctx, r.opts.Prefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			r.reportError("get", err)
+		}
+		r.misses.Add(1)
+		return zero, false
+	}
+
+	value, err := r.decode(data)
+	if err != nil {
+		r.misses.Add(1)
+		return zero, false
+	}
+
+	if r.opts.L1 != nil {
+		r.opts.L1.Set(key, value, r.opts.L1TTL)
+	}
+	r.hits.Add(1)
+	return value, true
+}
+
+func (r *Redis[V]) Set(key string, value V, ttl time.Duration) {
+	if ttl == DefaultExpiration {
+		ttl = r.opts.DefaultTTL
+	}
+	if ttl < 0 {
+		// В go-redis нулевой TTL означает запись без истечения
+		ttl = 0
+	}
+
+	if r.opts.L1 != nil {
+		l1TTL := r.opts.L1TTL
+		if ttl > 0 && ttl < l1TTL {
+			l1TTL = ttl
+		}
+		r.opts.L1.Set(key, value, l1TTL)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		r.reportError("encode", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.opts.Timeout)
+	defer cancel()
+
+	if err := r.client.Set(ctx, r.opts.Prefix+key, data, ttl).Err(); err != nil {
+		r.reportError("set", err)
+	}
+}
+
+func (r *Redis[V]) Delete(key string) {
+	if r.opts.L1 != nil {
+		r.opts.L1.Delete(key)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.opts.Timeout)
+	defer cancel()
+
+	removed, err := r.client.Del(ctx, r.opts.Prefix+key).Result()
+	if err != nil {
+		r.reportError("delete", err)
+		return
+	}
+	r.evictions.Add(uint64(removed))
+}
+
+func (r *Redis[V]) GetOrLoad(key string, ttl time.Duration, load func() (V, error)) (V, error) {
+	return getOrLoad[string, V](r, key, ttl, load)
+}
+
+// Len возвращает число ключей с префиксом во всём Redis, а не только записанных этой репликой.
+// Требует полного SCAN, поэтому не подходит для регулярного опроса.
+func (r *Redis[V]) Len() int {
+	return len(r.Keys())
+}
+
+// Keys перечисляет ключи через ScanKeys; неполный результат сообщается через OnError
+func (r *Redis[V]) Keys() []string {
+	keys, err := r.ScanKeys(context.Background())
+	if err != nil {
+		r.reportError("scan", err)
+	}
+	return keys
+}
+
+// ScanKeys перечисляет ключи с префиксом во всём Redis. Timeout ограничивает каждый шаг SCAN,
+// а не весь обход, поэтому большой keyspace не обрезается. При ошибке возвращаются уже найденные
+// ключи вместе с ErrKeysTruncated.
+func (r *Redis[V]) ScanKeys(ctx context.Context) ([]string, error) {
+	var (
+		keys   []string
+		cursor uint64
+	)
+	for {
+		stepCtx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+		page, next, err := r.client.Scan(stepCtx, cursor, r.opts.Prefix+"*", redisScanCount).Result()
+		cancel()
+		if err != nil {
+			return keys, fmt.Errorf("%w after %d keys: %w", ErrKeysTruncated, len(keys), err)
+		}
+
+		for _, key := range page {
+			keys = append(keys, strings.TrimPrefix(key, r.opts.Prefix))
+		}
+		if next == 0 {
+			return keys, nil
+		}
+		cursor = next
+	}
+}
+
+// Range читает значения напрямую из Redis, минуя L1. Ключи, истёкшие во время обхода, пропускаются.
+func (r *Redis[V]) Range(fn func(key string, value V) bool) {
+	for _, key := range r.Keys() {
+		ctx, cancel := context.WithTimeout(context.Background(), r.opts.Timeout)
+		data, err := r.client.Get(ctx, r.opts.Prefix+key).Bytes()
+		cancel()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) {
+				r.reportError("get", err)
+			}
+			continue
+		}
+
+		value, err := r.decode(data)
+		if err != nil {
+			continue
+		}
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+// FlushLocal очищает только L1, общий Redis не затрагивается
+func (r *Redis[V]) FlushLocal() {
+	if r.opts.L1 != nil {
+		r.opts.L1.Flush()
+	}
+}
+
+// DeleteLocal удаляет ключ только из L1: запись в Redis принадлежит всем репликам
+func (r *Redis[V]) DeleteLocal(key string) {
+	if r.opts.L1 != nil {
+		r.opts.L1.Delete(key)
+	}
+}
+
+// Flush удаляет все ключи с префиксом, в том числе записанные другими репликами, и очищает L1
+func (r *Redis[V]) Flush() {
+	if r.opts.L1 != nil {
+		r.opts.L1.Flush()
+	}
+
+	keys := r.Keys()
+	for start := 0; start < len(keys); start += redisScanCount {
+		batch := keys[start:min(start+redisScanCount, len(keys))]
+		for i, key := range batch {
+			batch[i] = r.opts.Prefix + key
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), r.opts.Timeout)
+		removed, err := r.client.Del(ctx, batch...).Result()
+		cancel()
+		if err != nil {
+			r.reportError("flush", err)
+			return
+		}
+		r.evictions.Add(uint64(removed))
+	}
+}
+
+// Stats возвращает статистику кэша без обращения к Redis. Items не считается: это потребовало бы
+// полного SCAN общего Redis. Evictions учитывает только явные удаления: истечение TTL не отслеживается.
+func (r *Redis[V]) Stats() Stats {
+	return Stats{
+		Items:     ItemsUnknown,
+		Hits:      r.hits.Load(),
+		Misses:    r.misses.Load(),
+		Evictions: r.evictions.Load(),
+	}
+}
+
+// Ping проверяет доступность Redis
+func (r *Redis[V]) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *Redis[V]) Close() error {
+	return r.client.Close()
+}
+
+func (r *Redis[V]) decode(data []byte) (V, error) {
+	var value V
+	if err := json.Unmarshal(data, &value); err != nil {
+		r.reportError("decode", err)
+		return value, err
+	}
+	return value, nil
+}
+
+func (r *Redis[V]) reportError(op string, err error) {
+	if r.opts.OnError != nil {
+		r.opts.OnError(op, err)
+	}
+}
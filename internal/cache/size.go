@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"encoding/json"
+	"unsafe"
+)
+
+// entryOverhead - примерные накладные расходы на запись: элемент списка, ячейка map, метаданные
+const entryOverhead = 128
+
+// Sizer реализуется значениями, которые сами оценивают свой объём в памяти
+type Sizer interface {
+	Size() int64
+}
+
+// EstimateSize оценивает объём памяти, занимаемый значением.
+// Значения, реализующие Sizer, оценивают себя сами, для прочих типов используется длина JSON.
+func EstimateSize(value interface{}) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case Sizer:
+		return v.Size()
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return 0
+		}
+		return int64(len(data))
+	}
+}
+
+// keySize оценивает размер ключа: для строк - длина, для прочих типов - размер значения в памяти
+func keySize[K comparable](key K) int64 {
+	if s, ok := any(key).(string); ok {
+		return int64(len(s))
+	}
+	return int64(unsafe.Sizeof(key))
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotVersion меняется при несовместимом изменении формата файла
+const snapshotVersion = 1
+
+var (
+	// ErrSnapshotCorrupted - файл повреждён: не разбирается или не совпадает контрольная сумма
+	ErrSnapshotCorrupted = errors.New("cache snapshot is corrupted")
+	// ErrSnapshotOutdated - снимок старше допустимого возраста или другой версии формата
+	ErrSnapshotOutdated = errors.New("cache snapshot is outdated")
+)
+
+// Snapshot - содержимое кэша на момент TakenAt
+type Snapshot[V any] struct {
+	TakenAt time.Time
+	Entries []SnapshotEntry[V]
+}
+
+type SnapshotEntry[V any] struct {
+	Key   string `json:"key"`
+	Value V      `json:"value"`
+}
+
+// snapshotHeader - первая строка файла. Контрольная сумма считается по остальной части файла.
+type snapshotHeader struct {
+	Version int       `json:"version"`
+	TakenAt time.Time `json:"taken_at"`
+	Entries int       `json:"entries"`
+	SHA256  string    `json:"sha256"`
+}
+
+// WriteSnapshot сохраняет непросроченные записи кэша в файл в порядке Range. Файл заменяется атомарно,
+// поэтому при сбое во время записи остаётся предыдущий снимок.
+func WriteSnapshot[V any](path string, c Cache[string, V], takenAt time.Time) (int, error) {
+	entries := make([]SnapshotEntry[V], 0, c.Len())
+	c.Range(func(key string, value V) bool {
+		entries = append(entries, SnapshotEntry[V]{Key: key, Value: value})
+		return true
+	})
+
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode cache snapshot: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	header, err := json.Marshal(snapshotHeader{
+		Version: snapshotVersion,
+		TakenAt: takenAt,
+		Entries: len(entries),
+		SHA256:  hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode cache snapshot header: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create cache snapshot: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	w := bufio.NewWriter(tmp)
+	_, _ = w.Write(header)
+	_ = w.WriteByte('\n')
+	_, _ = w.Write(body)
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return 0, fmt.Errorf("failed to write cache snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return 0, fmt.Errorf("failed to sync cache snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close cache snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to replace cache snapshot: %w", err)
+	}
+
+	return len(entries), nil
+}
+
+// ReadSnapshot читает и проверяет снимок. Ошибки ErrSnapshotCorrupted и ErrSnapshotOutdated
+// означают, что снимок нельзя использовать; os.ErrNotExist - что его нет.
+func ReadSnapshot[V any](path string, maxAge time.Duration, now time.Time) (*Snapshot[V], error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rawHeader, body, found := bytes.Cut(data, []byte{'\n'})
+	if !found {
+		return nil, fmt.Errorf("%w: missing header", ErrSnapshotCorrupted)
+	}
+
+	var header snapshotHeader
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, fmt.Errorf("%w: invalid header: %w", ErrSnapshotCorrupted, err)
+	}
+	if header.Version != snapshotVersion {
+		return nil, fmt.Errorf("%w: version %d, expected %d", ErrSnapshotOutdated, header.Version, snapshotVersion)
+	}
+
+	sum := sha256.Sum256(body)
+	if hex.EncodeToString(sum[:]) != header.SHA256 {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrSnapshotCorrupted)
+	}
+
+	if maxAge > 0 && now.Sub(header.TakenAt) > maxAge {
+		return nil, fmt.Errorf("%w: taken at %s", ErrSnapshotOutdated, header.TakenAt.Format(time.RFC3339))
+	}
+
+	var entries []SnapshotEntry[V]
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("%w: invalid entries: %w", ErrSnapshotCorrupted, err)
+	}
+	if len(entries) != header.Entries {
+		return nil, fmt.Errorf("%w: %d entries, expected %d", ErrSnapshotCorrupted, len(entries), header.Entries)
+	}
+
+	return &Snapshot[V]{TakenAt: header.TakenAt, Entries: entries}, nil
+}
@@ -16,19 +16,189 @@ type Config struct {
 	DBPassword string     `yaml:"db_password" env-required:"true"`
 	DBName     string     `yaml:"db_name" env-required:"true"`
 	HTTPServer HTTPServer `yaml:"http_server"`
+	Source     Source     `yaml:"source"`
 	Kafka      Kafka      `yaml:"kafka"`
+	Cache      Cache      `yaml:"cache"`
+	Health     Health     `yaml:"health"`
+	Admin      Admin      `yaml:"admin"`
 }
 
 type HTTPServer struct {
-	Address     string        `yaml:"address" env-default:"localhost:8080"`
-	Timeout     time.Duration `yaml:"timeout" env-default:"4s"`
-	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	Address string `yaml:"address" env-default:"localhost:8080"`
+	// Timeout используется для read/write/read_header таймаутов, если они не заданы явно
+	Timeout           time.Duration `yaml:"timeout" env-default:"4s"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env-default:"1048576"`
+	TLS               TLS           `yaml:"tls"`
 }
 
+// TLS - настройки HTTPS. Сертификат перечитывается с диска по SIGHUP.
+type TLS struct {
+	Enabled    bool   `yaml:"enabled"`
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	MinVersion string `yaml:"min_version" env-default:"1.2"` // 1.0 | 1.1 | 1.2 | 1.3
+}
+
+// Source - откуда читаются заказы и события статусов
+type Source struct {
+	Type string     `yaml:"type" env-default:"kafka"` // kafka | nats | file | stdin
+	NATS NATSSource `yaml:"nats"`
+	File FileSource `yaml:"file"`
+}
+
+// NATSSource - чтение из NATS JetStream. Поток должен существовать и включать оба subject.
+type NATSSource struct {
+	URL     string `yaml:"url" env-default:"nats://localhost:4222"`
+	Stream  string `yaml:"stream" env-default:"ORDERS"`
+	Subject string `yaml:"subject" env-default:"orders"`
+	// StatusSubject - subject событий смены статуса; пусто - не читаются
+	StatusSubject string `yaml:"status_subject"`
+	// Durable - имя durable-консьюмера заказов; для статусов добавляется суффикс _status
+	Durable string `yaml:"durable" env-default:"l0"`
+	// AckWait должен покрывать обработку одного сообщения со всеми повторами, иначе оно будет доставлено снова
+	AckWait time.Duration `yaml:"ack_wait" env-default:"2m"`
+	// MaxAckPending - сколько сообщений выдаётся без подтверждения, не меньше kafka.batch_size
+	MaxAckPending int `yaml:"max_ack_pending" env-default:"100"`
+}
+
+// FileSource - чтение NDJSON из файла или каталога (*.ndjson, *.jsonl)
+type FileSource struct {
+	Path string `yaml:"path"`
+	// StatusPath - файл или каталог с событиями смены статуса; пусто - не читаются
+	StatusPath   string        `yaml:"status_path"`
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"`
+}
+
+// Kafka - подключение к Kafka и обработка входящих сообщений. Workers, пачки и retry
+// действуют для любого источника; brokers обязательны для source.type kafka, DLQ и outbox.
 type Kafka struct {
-	Brokers []string `yaml:"brokers" env-required:"true"`
-	Topic   string   `yaml:"topic" env-required:"true"`
-	GroupID string   `yaml:"group_id" env-required:"true"`
+	Brokers  []string `yaml:"brokers"`
+	Topic    string   `yaml:"topic" env-default:"orders"`
+	GroupID  string   `yaml:"group_id" env-default:"l0_group"` // для топика статусов добавляется суффикс _status
+	DLQTopic string   `yaml:"dlq_topic"`                       // пустое значение отключает DLQ
+	// StatusTopic - топик событий смены статуса от склада и доставки; пусто - не читается
+	StatusTopic string `yaml:"status_topic"`
+	// Workers - число параллельных обработчиков; порядок сохраняется внутри партиции
+	Workers int `yaml:"workers" env-default:"1"`
+	// BatchSize - сколько заказов сохранять одной транзакцией; 1 - по одному
+	BatchSize    int           `yaml:"batch_size" env-default:"1"`
+	BatchTimeout time.Duration `yaml:"batch_timeout" env-default:"100ms"` // ожидание наполнения пачки
+	Retry        KafkaRetry    `yaml:"retry"`
+	Outbox       Outbox        `yaml:"outbox"`
+	// PendingStatus - события смены статуса, пришедшие раньше заказа
+	PendingStatus PendingStatus `yaml:"pending_status"`
+}
+
+// PendingStatus - повторное применение и срок хранения отложенных событий смены статуса
+type PendingStatus struct {
+	// TTL - сколько ждать заказ; более старые отложенные события удаляются
+	TTL time.Duration `yaml:"ttl" env-default:"168h"`
+	// Interval - как часто применять отложенные события сохранённых заказов и удалять устаревшие
+	Interval  time.Duration `yaml:"interval" env-default:"1m"`
+	BatchSize int           `yaml:"batch_size" env-default:"100"`
+}
+
+// Outbox - публикация событий о сохранённых заказах через transactional outbox
+type Outbox struct {
+	Enabled   bool          `yaml:"enabled"`
+	Topic     string        `yaml:"topic" env-default:"orders_persisted"`
+	BatchSize int           `yaml:"batch_size" env-default:"100"`
+	Interval  time.Duration `yaml:"interval" env-default:"1s"` // пауза, если outbox пуст
+	// PublishTimeout - предел публикации пакета, на это время открыта транзакция outbox
+	PublishTimeout time.Duration `yaml:"publish_timeout" env-default:"10s"`
+}
+
+// KafkaRetry - политика повторного сохранения заказа из сообщения
+type KafkaRetry struct {
+	MaxAttempts    int           `yaml:"max_attempts" env-default:"5"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" env-default:"500ms"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" env-default:"30s"`
+	Multiplier     float64       `yaml:"multiplier" env-default:"2"`
+	Jitter         float64       `yaml:"jitter" env-default:"0.2"`
+	Fallback       string        `yaml:"fallback" env-default:"dlq"` // dlq | skip
+}
+
+// Cache - выбор реализации кэша и её ограничения
+type Cache struct {
+	Type string `yaml:"type" env-default:"gocache"` // gocache | lru | redis
+	// TTL записей по умолчанию, отрицательное значение - без истечения
+	TTL time.Duration `yaml:"ttl" env-default:"5m"`
+	// Период очистки истёкших записей gocache и lru
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env-default:"10m"`
+	// Лимиты LRU-кэша, 0 - без ограничения. Для redis ограничивают локальный L1.
+	MaxEntries int        `yaml:"max_entries"`
+	MaxBytes   int64      `yaml:"max_bytes"`
+	Redis      CacheRedis `yaml:"redis"`
+	// Сколько помнить, что заказа нет в БД. Отрицательные записи локальны для реплики.
+	NegativeTTL        time.Duration `yaml:"negative_ttl" env-default:"5s"`
+	NegativeMaxEntries int           `yaml:"negative_max_entries" env-default:"10000"`
+	Warmup             CacheWarmup   `yaml:"warmup"`
+	RefreshAhead       RefreshAhead  `yaml:"refresh_ahead"`
+	Invalidation       Invalidation  `yaml:"invalidation"`
+	Snapshot           CacheSnapshot `yaml:"snapshot"`
+}
+
+// CacheSnapshot - снимок кэша на диске для быстрого перезапуска
+type CacheSnapshot struct {
+	Path     string        `yaml:"path"` // пусто - снимки выключены
+	Interval time.Duration `yaml:"interval" env-default:"5m"`
+	MaxAge   time.Duration `yaml:"max_age" env-default:"1h"`
+}
+
+// Invalidation - сброс устаревших записей в кэшах других реплик через Postgres LISTEN/NOTIFY
+type Invalidation struct {
+	Enabled      bool          `yaml:"enabled"`
+	Channel      string        `yaml:"channel" env-default:"order_changes"`
+	MinReconnect time.Duration `yaml:"min_reconnect" env-default:"1s"`
+	MaxReconnect time.Duration `yaml:"max_reconnect" env-default:"1m"`
+}
+
+// CacheRedis - подключение к Redis для распределённого кэша
+type CacheRedis struct {
+	Addr     string        `yaml:"addr" env-default:"localhost:6379"`
+	Password string        `yaml:"password"`
+	DB       int           `yaml:"db"`
+	Prefix   string        `yaml:"prefix" env-default:"l0:order:"`
+	Timeout  time.Duration `yaml:"timeout" env-default:"500ms"`
+	L1TTL    time.Duration `yaml:"l1_ttl" env-default:"30s"`
+	// DisableL1 отключает локальный уровень: каждое чтение идёт в Redis
+	DisableL1 bool `yaml:"disable_l1"`
+}
+
+// CacheWarmup - параметры прогрева кэша из БД при старте
+type CacheWarmup struct {
+	// BatchSize - размер страницы выборки, не больше repository.MaxBatchSize
+	BatchSize int           `yaml:"batch_size" env-default:"500"`
+	MaxOrders int           `yaml:"max_orders"` // 0 - все заказы
+	Window    time.Duration `yaml:"window"`     // 0 - без ограничения по date_created
+	// full - снимок (если настроен) и догрузка из БД, none - кэш наполняется только по запросам
+	Mode string `yaml:"mode" env-default:"full"`
+	// TTL прогретых и восстановленных из снимка записей: 0 - cache.ttl, отрицательное - без истечения
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// RefreshAhead - фоновое обновление записей незадолго до истечения (stale-while-revalidate).
+// Не работает с redis: время истечения записи известно только локальным кэшам.
+type RefreshAhead struct {
+	Enabled bool          `yaml:"enabled"`
+	Window  time.Duration `yaml:"window" env-default:"1m"` // за сколько до истечения обновлять запись
+}
+
+// Health - параметры проверки готовности (/readyz)
+type Health struct {
+	CheckTimeout     time.Duration `yaml:"check_timeout" env-default:"2s"`
+	KafkaFetchWindow time.Duration `yaml:"kafka_fetch_window" env-default:"1m"`
+}
+
+// Admin - API администрирования на отдельном listener'е
+type Admin struct {
+	Address string `yaml:"address"` // пусто - API выключено
+	// Token передаётся в заголовке "Authorization: Bearer <token>"; пусто - без проверки
+	Token string `yaml:"token"`
 }
 
 func MustLoad() *Config {
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"L0/internal/service"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AdminHandler struct {
+	CacheAdmin service.CacheAdmin
+	Logger     *slog.Logger
+}
+
+func NewAdminHandler(cacheAdmin service.CacheAdmin, logger *slog.Logger) *AdminHandler {
+	return &AdminHandler{
+		CacheAdmin: cacheAdmin,
+		Logger:     logger,
+	}
+}
+
+// CacheStats отдаёт размер, счётчики и самую старую запись кэша
+func (h *AdminHandler) CacheStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.CacheAdmin.Stats())
+}
+
+// EvictOrder удаляет один заказ из кэша. Отсутствие заказа в кэше ошибкой не считается.
+func (h *AdminHandler) EvictOrder(w http.ResponseWriter, r *http.Request) {
+	orderUID := chi.URLParam(r, "order_uid")
+	if orderUID == "" || len(orderUID) > 100 {
+		http.Error(w, "order_uid must be between 1 and 100 characters", http.StatusBadRequest)
+		return
+	}
+
+	h.CacheAdmin.Evict(orderUID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FlushCache удаляет из кэша все заказы
+func (h *AdminHandler) FlushCache(w http.ResponseWriter, r *http.Request) {
+	h.CacheAdmin.Flush()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RewarmCache запускает прогрев кэша из БД. Ход прогрева виден в CacheStats.
+func (h *AdminHandler) RewarmCache(w http.ResponseWriter, r *http.Request) {
+	if err := h.CacheAdmin.Rewarm(); err != nil {
+		if errors.Is(err, service.ErrRewarmInProgress) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		h.Logger.Error("Failed to start cache rewarm", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// Stats перечисляет ключи кэша, а для ответа нужно только состояние прогрева
+	writeJSON(w, http.StatusAccepted, h.CacheAdmin.RewarmStatus())
+}
+
+// RequireToken пропускает только запросы с заголовком "Authorization: Bearer <token>".
+// Пустой token отключает проверку.
+func RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if token == "" {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
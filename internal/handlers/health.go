@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"L0/internal/health"
+	"log/slog"
+	"net/http"
+)
+
+type HealthHandler struct {
+	Checker *health.Checker
+	Logger  *slog.Logger
+}
+
+func NewHealthHandler(checker *health.Checker, logger *slog.Logger) *HealthHandler {
+	return &HealthHandler{
+		Checker: checker,
+		Logger:  logger,
+	}
+}
+
+// Liveness отвечает, пока процесс способен обслуживать HTTP
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, health.ComponentStatus{Status: health.StatusOK})
+}
+
+// Readiness проверяет зависимости и отдаёт состояние каждой из них
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.Checker.Check(r.Context())
+	if !report.Healthy() {
+		h.Logger.Warn("Readiness check failed", slog.Any("components", report.Components))
+		writeJSON(w, http.StatusServiceUnavailable, report)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
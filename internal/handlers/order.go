@@ -1,24 +1,51 @@
 package handlers
 
 import (
+	"L0/internal/kafka/dto"
+	"L0/internal/models"
+	"L0/internal/repository"
 	"L0/internal/service"
+	"L0/internal/validation"
 	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"gorm.io/gorm"
 )
 
+const (
+	// maxOrderBodySize ограничивает размер тела POST /orders
+	maxOrderBodySize = 1 << 20 // 1MB
+	// maxStatusBodySize ограничивает размер тела PATCH /orders/{order_uid}/status
+	maxStatusBodySize = 4 << 10 // 4KB
+	// maxStatusReasonLength - максимальная длина причины смены статуса
+	maxStatusReasonLength = 500
+)
+
 type OrderHandler struct {
 	OrderService service.OrderService
+	Validator    *validation.OrderValidator
 	Logger       *slog.Logger
 }
 
+type updateStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type validationErrorResponse struct {
+	Error  string                      `json:"error"`
+	Errors validation.ValidationErrors `json:"errors"`
+}
+
 func NewOrderHandler(orderService service.OrderService, logger *slog.Logger) *OrderHandler {
 	return &OrderHandler{
 		OrderService: orderService,
+		Validator:    validation.NewOrderValidator(),
 		Logger:       logger,
 	}
 }
@@ -60,3 +87,192 @@ func (h *OrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	h.Logger.Debug("Order retrieved successfully", slog.String("order_uid", orderUID))
 }
+
+// Create принимает заказ по HTTP и проводит его через ту же валидацию, что и сообщения из Kafka
+func (h *OrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var order dto.OrderDTO
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxOrderBodySize)).Decode(&order); err != nil {
+		h.Logger.Info("Invalid order payload", slog.String("error", err.Error()))
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Validator.ValidateOrder(&order); err != nil {
+		var vErrors validation.ValidationErrors
+		if !errors.As(err, &vErrors) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		h.Logger.Info("Order validation failed",
+			slog.String("order_uid", order.OrderUID),
+			slog.String("validation_error", err.Error()))
+		writeJSON(w, http.StatusUnprocessableEntity, validationErrorResponse{
+			Error:  "validation failed",
+			Errors: vErrors,
+		})
+		return
+	}
+
+	created, err := h.OrderService.CreateOrder(r.Context(), &order)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderExists) {
+			h.Logger.Info("Order already exists", slog.String("order_uid", order.OrderUID))
+			http.Error(w, "order already exists", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, repository.ErrTrackNumberTaken) {
+			h.Logger.Info("Order track number is already used", slog.String("order_uid", order.OrderUID))
+			writeJSON(w, http.StatusUnprocessableEntity, validationErrorResponse{
+				Error: "validation failed",
+				Errors: validation.ValidationErrors{{
+					Field:   "track_number",
+					Tag:     "unique",
+					Message: "track_number is already used by another order",
+					Value:   order.TrackNumber,
+				}},
+			})
+			return
+		}
+
+		h.Logger.Error("Failed to create order", slog.String("error", err.Error()), slog.String("order_uid", order.OrderUID))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", "/orders/"+created.OrderUID)
+	writeJSON(w, http.StatusCreated, created)
+
+	h.Logger.Info("Order created via HTTP", slog.String("order_uid", created.OrderUID))
+}
+
+// UpdateStatus переводит заказ в новый статус. Недопустимый переход и одновременная
+// смена статуса другим запросом возвращают 409.
+func (h *OrderHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	orderUID := chi.URLParam(r, "order_uid")
+	if len(orderUID) == 0 || len(orderUID) > 100 {
+		http.Error(w, "order_uid must be between 1 and 100 characters", http.StatusBadRequest)
+		return
+	}
+
+	var req updateStatusRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxStatusBodySize)).Decode(&req); err != nil {
+		h.Logger.Info("Invalid status payload", slog.String("error", err.Error()))
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status, err := models.ParseOrderStatus(req.Status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Reason) > maxStatusReasonLength {
+		http.Error(w, "reason must be at most "+strconv.Itoa(maxStatusReasonLength)+" characters", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.OrderService.UpdateOrderStatus(r.Context(), orderUID, status, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			http.Error(w, "order not found", http.StatusNotFound)
+		case errors.Is(err, models.ErrIllegalTransition):
+			h.Logger.Info("Illegal order status transition",
+				slog.String("order_uid", orderUID),
+				slog.String("error", err.Error()))
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, repository.ErrStatusConflict):
+			http.Error(w, "order status was changed concurrently, retry the request", http.StatusConflict)
+		default:
+			h.Logger.Error("Failed to update order status", slog.String("error", err.Error()), slog.String("order_uid", orderUID))
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, order)
+}
+
+// List возвращает страницу заказов с фильтрами и курсорной пагинацией
+func (h *OrderHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseOrderFilter(r)
+	if err != nil {
+		h.Logger.Info("Invalid list query", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.OrderService.ListOrders(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+
+		h.Logger.Error("Failed to list orders", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		h.Logger.Error("Failed to encode response", slog.String("error", err.Error()))
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func parseOrderFilter(r *http.Request) (repository.OrderFilter, error) {
+	q := r.URL.Query()
+
+	filter := repository.OrderFilter{
+		CustomerID:      q.Get("customer_id"),
+		DeliveryService: q.Get("delivery_service"),
+		Entry:           q.Get("entry"),
+		Locale:          q.Get("locale"),
+		Cursor:          q.Get("cursor"),
+	}
+
+	if raw := q.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > repository.MaxPageSize {
+			return filter, errors.New("limit must be an integer between 1 and " + strconv.Itoa(repository.MaxPageSize))
+		}
+		filter.Limit = limit
+	}
+
+	if raw := q.Get("date_from"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, errors.New("date_from must be in RFC3339 format")
+		}
+		filter.DateFrom = &t
+	}
+
+	if raw := q.Get("date_to"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, errors.New("date_to must be in RFC3339 format")
+		}
+		filter.DateTo = &t
+	}
+
+	if filter.DateFrom != nil && filter.DateTo != nil && !filter.DateFrom.Before(*filter.DateTo) {
+		return filter, errors.New("date_from must be before date_to")
+	}
+
+	if filter.Cursor != "" {
+		if _, err := repository.DecodeCursor(filter.Cursor); err != nil {
+			return filter, err
+		}
+	}
+
+	return filter, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
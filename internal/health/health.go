@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusDown = "down"
+)
+
+// Check проверяет одну зависимость сервиса. nil означает, что зависимость доступна.
+type Check func(ctx context.Context) error
+
+// ComponentStatus - состояние одной зависимости
+type ComponentStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report - результат проверки готовности по всем зависимостям
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// Healthy сообщает, что все зависимости доступны
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// Checker хранит проверки зависимостей и выполняет их параллельно
+type Checker struct {
+	mu      sync.RWMutex
+	checks  map[string]Check
+	timeout time.Duration
+}
+
+// NewChecker создает Checker. timeout ограничивает время каждой проверки.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		checks:  make(map[string]Check),
+		timeout: timeout,
+	}
+}
+
+// Register добавляет проверку зависимости под именем name
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// Check выполняет все проверки и собирает отчёт
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	names := make([]string, 0, len(c.checks))
+	for name := range c.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = c.checks[name]
+	}
+	c.mu.RUnlock()
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+			errs[i] = check(checkCtx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:     StatusOK,
+		Components: make(map[string]ComponentStatus, len(names)),
+	}
+	for i, name := range names {
+		if errs[i] != nil {
+			report.Status = StatusDown
+			report.Components[name] = ComponentStatus{Status: StatusDown, Error: errs[i].Error()}
+			continue
+		}
+		report.Components[name] = ComponentStatus{Status: StatusOK}
+	}
+
+	return report
+}
+
+// Flag - признак готовности, который выставляется один раз, например после прогрева кэша
+type Flag struct {
+	ready  atomic.Bool
+	reason string
+}
+
+// NewFlag создает неготовый Flag. reason возвращается проверкой, пока флаг не выставлен.
+func NewFlag(reason string) *Flag {
+	return &Flag{reason: reason}
+}
+
+// MarkReady выставляет флаг готовности
+func (f *Flag) MarkReady() {
+	f.ready.Store(true)
+}
+
+// Ready сообщает, выставлен ли флаг
+func (f *Flag) Ready() bool {
+	return f.ready.Load()
+}
+
+// Check возвращает ошибку, пока флаг не выставлен
+func (f *Flag) Check(context.Context) error {
+	if !f.ready.Load() {
+		return errors.New(f.reason)
+	}
+	return nil
+}
+
+// Freshness возвращает проверку, которая падает, если событие last не происходило дольше window
+func Freshness(last func() time.Time, window time.Duration) Check {
+	return func(context.Context) error {
+		at := last()
+		if at.IsZero() {
+			return errors.New("not started")
+		}
+		if since := time.Since(at); since > window {
+			return fmt.Errorf("no activity for %s (window %s)", since.Truncate(time.Second), window)
+		}
+		return nil
+	}
+}
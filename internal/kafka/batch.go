@@ -0,0 +1,67 @@
+package kafka
+
+import (
+	"L0/internal/kafka/dto"
+	"L0/internal/metrics"
+	"L0/internal/source"
+	"context"
+	"log/slog"
+)
+
+type orderBatchProcessor struct {
+	logger *slog.Logger
+	single *orderMessageProcessor
+}
+
+// NewOrderBatchProcessor создает процессор, сохраняющий заказы пачками
+func NewOrderBatchProcessor(logger *slog.Logger, retry RetryPolicy) BatchMessageProcessor {
+	return &orderBatchProcessor{
+		logger: logger,
+		single: &orderMessageProcessor{
+			logger: logger,
+			retry:  retry,
+		},
+	}
+}
+
+// ProcessBatch сохраняет валидные заказы пачки одной транзакцией. Если транзакция не прошла,
+// заказы сохраняются по одному с обычными повторами, чтобы один плохой заказ не задерживал остальные.
+func (p *orderBatchProcessor) ProcessBatch(ctx context.Context, msgs []source.Message, sink BatchOrderSink) []error {
+	errs := make([]error, len(msgs))
+	orders := make([]*dto.OrderDTO, 0, len(msgs))
+	valid := make([]int, 0, len(msgs))
+	for i, m := range msgs {
+		order, err := p.single.decode(m.Value)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		orders = append(orders, order)
+		valid = append(valid, i)
+	}
+	if len(orders) == 0 {
+		return errs
+	}
+
+	_, err := sink.CreateOrders(ctx, orders)
+	if err == nil {
+		p.logger.Info("Order batch processed successfully", slog.Int("count", len(orders)))
+		return errs
+	}
+
+	if ctx.Err() == nil {
+		metrics.KafkaBatchFallbacks.WithLabelValues(msgs[0].Topic).Inc()
+		p.logger.Warn("Failed to save order batch, processing messages one by one",
+			slog.Int("count", len(orders)),
+			slog.String("error", err.Error()))
+	}
+
+	for k, i := range valid {
+		if ctx.Err() != nil {
+			errs[i] = ctx.Err()
+			continue
+		}
+		errs[i] = p.single.save(contextWithMessage(ctx, msgs[i]), orders[k], sink)
+	}
+	return errs
+}
@@ -0,0 +1,27 @@
+package kafka
+
+import (
+	"L0/internal/source"
+	"context"
+	"strconv"
+)
+
+type messageKey struct{}
+
+// messageMeta - координаты обрабатываемого сообщения, нужны процессору для меток метрик
+type messageMeta struct {
+	topic     string
+	partition string
+}
+
+func contextWithMessage(ctx context.Context, m source.Message) context.Context {
+	return context.WithValue(ctx, messageKey{}, messageMeta{
+		topic:     m.Topic,
+		partition: strconv.Itoa(m.Partition),
+	})
+}
+
+func messageFromContext(ctx context.Context) (messageMeta, bool) {
+	meta, ok := ctx.Value(messageKey{}).(messageMeta)
+	return meta, ok
+}
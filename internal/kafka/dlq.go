@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"L0/internal/source"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Заголовки, которые добавляются к сообщению при отправке в DLQ
+const (
+	HeaderDLQReason           = "dlq-reason"
+	HeaderDLQError            = "dlq-error"
+	HeaderDLQValidationErrors = "dlq-validation-errors"
+	HeaderDLQSourceTopic      = "dlq-source-topic"
+	HeaderDLQSourcePartition  = "dlq-source-partition"
+	HeaderDLQSourceOffset     = "dlq-source-offset"
+	HeaderDLQTimestamp        = "dlq-timestamp"
+)
+
+type kafkaDeadLetterPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewDeadLetterPublisher создает публикатор отклонённых сообщений в DLQ-топик
+func NewDeadLetterPublisher(brokers []string, topic string) DeadLetterPublisher {
+	return &kafkaDeadLetterPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+func (p *kafkaDeadLetterPublisher) Publish(ctx context.Context, msg source.Message, cause error) error {
+	return p.writer.WriteMessages(ctx, NewDeadLetterMessage(msg, cause, time.Now()))
+}
+
+func (p *kafkaDeadLetterPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// NewDeadLetterMessage копирует исходное сообщение и добавляет заголовки с причиной отказа,
+// ошибками валидации, координатами исходного сообщения и временем отправки в DLQ
+func NewDeadLetterMessage(src source.Message, cause error, now time.Time) kafka.Message {
+	reason := "unknown"
+	var rejectErr *RejectError
+	if errors.As(cause, &rejectErr) {
+		reason = rejectErr.Reason
+	}
+
+	headers := make([]kafka.Header, 0, len(src.Headers)+7)
+	for _, h := range src.Headers {
+		headers = append(headers, kafka.Header{Key: h.Key, Value: h.Value})
+	}
+	headers = append(headers,
+		kafka.Header{Key: HeaderDLQReason, Value: []byte(reason)},
+		kafka.Header{Key: HeaderDLQError, Value: []byte(cause.Error())},
+		kafka.Header{Key: HeaderDLQSourceTopic, Value: []byte(src.Topic)},
+		kafka.Header{Key: HeaderDLQSourcePartition, Value: []byte(strconv.Itoa(src.Partition))},
+		kafka.Header{Key: HeaderDLQSourceOffset, Value: []byte(strconv.FormatInt(src.Offset, 10))},
+		kafka.Header{Key: HeaderDLQTimestamp, Value: []byte(now.UTC().Format(time.RFC3339Nano))},
+	)
+
+	if rejectErr != nil {
+		if vErrors := rejectErr.ValidationErrors(); len(vErrors) > 0 {
+			if data, err := json.Marshal(vErrors); err == nil {
+				headers = append(headers, kafka.Header{Key: HeaderDLQValidationErrors, Value: data})
+			}
+		}
+	}
+
+	return kafka.Message{
+		Key:     src.Key,
+		Value:   src.Value,
+		Headers: headers,
+	}
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "unsafe"
+
 type OrderDTO struct {
 	OrderUID          string      `json:"order_uid" validate:"required,min=1,max=100"`
 	TrackNumber       string      `json:"track_number" validate:"required,min=1,max=100"`
@@ -15,4 +17,45 @@ type OrderDTO struct {
 	SmID              uint64      `json:"sm_id" validate:"required,min=1"`
 	DateCreated       string      `json:"date_created" validate:"required,datetime=2006-01-02T15:04:05Z"`
 	OofShard          string      `json:"oof_shard" validate:"required,min=1,max=10"`
+	// Status задаётся сервисом: новый заказ всегда получает статус created
+	Status string `json:"status,omitempty"`
+}
+
+// Clone возвращает глубокую копию заказа: изменения копии не затрагивают оригинал
+func (o *OrderDTO) Clone() *OrderDTO {
+	if o == nil {
+		return nil
+	}
+
+	clone := *o
+	if o.Items != nil {
+		clone.Items = make([]ItemDTO, len(o.Items))
+		copy(clone.Items, o.Items)
+	}
+	return &clone
+}
+
+// Size оценивает объём памяти, занимаемый заказом, для лимита memory budget кэша
+func (o *OrderDTO) Size() int64 {
+	if o == nil {
+		return 0
+	}
+
+	size := int64(unsafe.Sizeof(*o)) +
+		int64(len(o.OrderUID)+len(o.TrackNumber)+len(o.Entry)+len(o.Locale)+
+			len(o.InternalSignature)+len(o.CustomerID)+len(o.DeliveryService)+
+			len(o.Shardkey)+len(o.DateCreated)+len(o.OofShard)+len(o.Status))
+
+	d := o.Delivery
+	size += int64(len(d.Name) + len(d.Phone) + len(d.Zip) + len(d.City) + len(d.Address) + len(d.Region) + len(d.Email))
+
+	p := o.Payment
+	size += int64(len(p.Transaction) + len(p.RequestID) + len(p.Currency) + len(p.Provider) + len(p.Bank))
+
+	size += int64(cap(o.Items)) * int64(unsafe.Sizeof(ItemDTO{}))
+	for _, it := range o.Items {
+		size += int64(len(it.TrackNumber) + len(it.RID) + len(it.Name) + len(it.Size) + len(it.Brand))
+	}
+
+	return size
 }
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// EventOrderPersisted - тип события о сохранённом заказе
+const EventOrderPersisted = "order.persisted"
+
+// OrderPersistedEvent публикуется после того, как заказ надёжно сохранён в БД
+type OrderPersistedEvent struct {
+	// EventID одинаков при повторной публикации: доставка at-least-once, потребители отбрасывают дубли по нему
+	EventID     string    `json:"event_id"`
+	Type        string    `json:"type"`
+	OrderUID    string    `json:"order_uid"`
+	PersistedAt time.Time `json:"persisted_at"`
+	Order       *OrderDTO `json:"order"`
+}
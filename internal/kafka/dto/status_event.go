@@ -0,0 +1,12 @@
+package dto
+
+// StatusEventDTO - событие смены статуса заказа от склада или службы доставки
+type StatusEventDTO struct {
+	// EventID - ключ идемпотентности: повторно доставленное событие не применяется
+	EventID    string `json:"event_id" validate:"required,min=1,max=100"`
+	OrderUID   string `json:"order_uid" validate:"required,min=1,max=100"`
+	Status     string `json:"status" validate:"required"`
+	Reason     string `json:"reason" validate:"max=500"`
+	Source     string `json:"source" validate:"max=100"`
+	OccurredAt string `json:"occurred_at" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+}
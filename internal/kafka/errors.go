@@ -0,0 +1,40 @@
+package kafka
+
+import (
+	"L0/internal/validation"
+	"errors"
+)
+
+// Причины, по которым сообщение отправляется в DLQ
+const (
+	ReasonInvalidFormat    = "invalid_format"
+	ReasonValidationFailed = "validation_failed"
+	ReasonPersistFailed    = "persist_failed"
+	ReasonRetriesExhausted = "retries_exhausted"
+	// ReasonIllegalTransition - событие переводит заказ в статус, недопустимый из текущего
+	ReasonIllegalTransition = "illegal_transition"
+)
+
+// RejectError означает, что сообщение не может быть обработано никогда
+// и должно быть отправлено в DLQ вместо повторной обработки
+type RejectError struct {
+	Reason string
+	Err    error
+}
+
+func (e *RejectError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RejectError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors возвращает ошибки валидации заказа, если они стали причиной отказа
+func (e *RejectError) ValidationErrors() validation.ValidationErrors {
+	var vErrors validation.ValidationErrors
+	if errors.As(e.Err, &vErrors) {
+		return vErrors
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"L0/internal/repository"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Заголовки сообщений, публикуемых из outbox
+const (
+	HeaderEventType = "event-type"
+	HeaderOutboxID  = "outbox-id"
+)
+
+// eventBatchTimeout - сколько writer ждёт накопления пакета. События уходят пачками из outbox,
+// поэтому ждать дольше стандартной секунды нет смысла.
+const eventBatchTimeout = 10 * time.Millisecond
+
+type kafkaEventPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewEventPublisher создает публикатор событий outbox. Ключ сообщения - order_uid:
+// события одного заказа попадают в одну партицию и читаются в порядке публикации.
+func NewEventPublisher(brokers []string, topic string) EventPublisher {
+	return &kafkaEventPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			BatchTimeout:           eventBatchTimeout,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+func (p *kafkaEventPublisher) PublishEvents(ctx context.Context, events []repository.OutboxEvent) error {
+	messages := make([]kafka.Message, 0, len(events))
+	for _, event := range events {
+		messages = append(messages, kafka.Message{
+			Key:   []byte(event.AggregateID),
+			Value: event.Payload,
+			Headers: []kafka.Header{
+				{Key: HeaderEventType, Value: []byte(event.EventType)},
+				{Key: HeaderOutboxID, Value: []byte(strconv.FormatUint(event.ID, 10))},
+			},
+			Time: event.CreatedAt,
+		})
+	}
+	return p.writer.WriteMessages(ctx, messages...)
+}
+
+func (p *kafkaEventPublisher) Close() error {
+	return p.writer.Close()
+}
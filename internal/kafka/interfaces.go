@@ -1,16 +1,63 @@
 package kafka
 
 import (
+	"L0/internal/kafka/dto"
 	"L0/internal/repository"
+	"L0/internal/source"
 	"context"
+	"time"
 )
 
-// Consumer интерфейс для потребления сообщений из Kafka
+// OrderSink принимает заказы, прочитанные из Kafka.
+// Реализуется service.OrderService, поэтому заказы попадают в кэш так же, как и через HTTP.
+type OrderSink interface {
+	CreateOrder(ctx context.Context, order *dto.OrderDTO) (*dto.OrderDTO, error)
+}
+
+// BatchOrderSink дополнительно умеет сохранять заказы пачкой в одной транзакции
+type BatchOrderSink interface {
+	OrderSink
+	CreateOrders(ctx context.Context, orders []*dto.OrderDTO) ([]*dto.OrderDTO, error)
+}
+
+// StatusEventSink принимает события смены статуса, прочитанные из Kafka.
+// Реализуется service.OrderService.
+type StatusEventSink interface {
+	ApplyStatusEvent(ctx context.Context, event *dto.StatusEventDTO) error
+}
+
+// Consumer интерфейс для потребления сообщений из источника: Kafka, NATS, файла или stdin.
+// Reader закрывается по завершении чтения.
 type Consumer interface {
-	ConsumeOrders(ctx context.Context, brokers []string, topic, groupID string, repo repository.Repository) error
+	ConsumeOrders(ctx context.Context, r source.Reader, sink OrderSink) error
+	ConsumeStatusEvents(ctx context.Context, r source.Reader, sink StatusEventSink) error
+	// LastFetch возвращает время последнего обращения reader'а к брокеру за сообщениями
+	LastFetch() time.Time
 }
 
 // MessageProcessor интерфейс для обработки сообщений
 type MessageProcessor interface {
-	ProcessMessage(ctx context.Context, data []byte, repo repository.Repository) error
+	ProcessMessage(ctx context.Context, data []byte, sink OrderSink) error
+}
+
+// BatchMessageProcessor обрабатывает пачку сообщений с заказами и возвращает ошибку для каждого сообщения
+type BatchMessageProcessor interface {
+	ProcessBatch(ctx context.Context, msgs []source.Message, sink BatchOrderSink) []error
+}
+
+// StatusEventProcessor интерфейс для обработки событий смены статуса
+type StatusEventProcessor interface {
+	ProcessMessage(ctx context.Context, data []byte, sink StatusEventSink) error
+}
+
+// EventPublisher публикует события outbox
+type EventPublisher interface {
+	PublishEvents(ctx context.Context, events []repository.OutboxEvent) error
+	Close() error
+}
+
+// DeadLetterPublisher интерфейс для отправки отклонённых сообщений в DLQ
+type DeadLetterPublisher interface {
+	Publish(ctx context.Context, msg source.Message, cause error) error
+	Close() error
 }
@@ -2,94 +2,444 @@ package kafka
 
 import (
 	"L0/internal/kafka/dto"
+	"L0/internal/metrics"
 	"L0/internal/repository"
+	"L0/internal/source"
 	"L0/internal/validation"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/lib/pq"
 	"github.com/segmentio/kafka-go"
 )
 
+const (
+	// dlqRetryInterval - пауза между попытками отправить сообщение в DLQ
+	dlqRetryInterval = 3 * time.Second
+	// fetchTrackInterval - как часто проверяется статистика reader'а для LastFetch
+	fetchTrackInterval = time.Second
+	// workerQueueSize - сколько прочитанных сообщений может ждать своего обработчика
+	workerQueueSize = 64
+	// commitTimeout ограничивает коммит смещений, в том числе последний коммит при остановке
+	commitTimeout = 5 * time.Second
+	// fetchErrorBackoff - пауза после ошибки чтения из источника
+	fetchErrorBackoff = time.Second
+	// defaultBatchTimeout - сколько по умолчанию ждать наполнения пачки
+	defaultBatchTimeout = 100 * time.Millisecond
+)
+
+// ConsumerOptions - параметры обработки сообщений
+type ConsumerOptions struct {
+	// Workers - число параллельных обработчиков. Каждая партиция закреплена за одним из них,
+	// поэтому сообщения партиции обрабатываются по порядку. По умолчанию 1.
+	Workers int
+	// BatchSize - сколько заказов обработчик сохраняет одной транзакцией; 1 - по одному.
+	// Пачки работают, если sink реализует BatchOrderSink.
+	BatchSize int
+	// BatchTimeout - сколько ждать наполнения пачки после её первого сообщения
+	BatchTimeout time.Duration
+}
+
+// batchOptions - размер пачки и время ожидания её наполнения
+type batchOptions struct {
+	size    int
+	timeout time.Duration
+}
+
+// processFunc обрабатывает пачку сообщений и возвращает ошибку для каждого из них
+type processFunc func(ctx context.Context, msgs []source.Message) []error
+
 type orderConsumer struct {
-	logger *slog.Logger
+	logger  *slog.Logger
+	dlq     DeadLetterPublisher
+	retry   RetryPolicy
+	workers int
+	batch   batchOptions
+
+	lastFetch atomic.Int64 // UnixNano
 }
 
 type orderMessageProcessor struct {
 	logger *slog.Logger
+	retry  RetryPolicy
 }
 
-// NewOrderConsumer создает новый экземпляр Kafka consumer для заказов
-func NewOrderConsumer(logger *slog.Logger) Consumer {
+// NewOrderConsumer создает новый экземпляр consumer для заказов.
+// Если dlq равен nil, отклонённые сообщения только логируются.
+func NewOrderConsumer(logger *slog.Logger, dlq DeadLetterPublisher, retry RetryPolicy, opts ConsumerOptions) Consumer {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1
+	}
+	if opts.BatchTimeout <= 0 {
+		opts.BatchTimeout = defaultBatchTimeout
+	}
 	return &orderConsumer{
-		logger: logger,
+		logger:  logger,
+		dlq:     dlq,
+		retry:   retry,
+		workers: opts.Workers,
+		batch:   batchOptions{size: opts.BatchSize, timeout: opts.BatchTimeout},
 	}
 }
 
 // NewOrderMessageProcessor создает новый экземпляр процессора сообщений для заказов
-func NewOrderMessageProcessor(logger *slog.Logger) MessageProcessor {
+func NewOrderMessageProcessor(logger *slog.Logger, retry RetryPolicy) MessageProcessor {
 	return &orderMessageProcessor{
 		logger: logger,
+		retry:  retry,
 	}
 }
 
-func (c *orderConsumer) ConsumeOrders(ctx context.Context, brokers []string, topic, groupID string, repo repository.Repository) error {
-	r := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:        brokers,
-		GroupID:        groupID,
-		Topic:          topic,
-		MinBytes:       10e3, // 10KB
-		MaxBytes:       10e6, // 10MB
-		CommitInterval: 0,
-	})
+func (c *orderConsumer) ConsumeOrders(ctx context.Context, r source.Reader, sink OrderSink) error {
+	defer c.closeReader(r)
+
+	c.logger.Info("Order consumer started",
+		slog.Int("workers", c.workers),
+		slog.Int("batch_size", c.batch.size))
+
+	// Отсчёт окна готовности начинается с запуска consumer
+	c.lastFetch.Store(time.Now().UnixNano())
+	if stats, ok := r.(readerStats); ok {
+		go c.trackFetches(ctx, stats)
+	}
+
+	batchSink, ok := sink.(BatchOrderSink)
+	if ok && c.batch.size > 1 {
+		processor := NewOrderBatchProcessor(c.logger, c.retry)
+		return c.consume(ctx, r, c.batch, func(ctx context.Context, msgs []source.Message) []error {
+			return processor.ProcessBatch(ctx, msgs, batchSink)
+		})
+	}
+	if c.batch.size > 1 {
+		c.logger.Warn("Order sink does not support batches, saving orders one by one")
+	}
 
-	defer func() {
-		if err := r.Close(); err != nil {
-			c.logger.Error("Failed to close Kafka connection", slog.String("error", err.Error()))
+	processor := NewOrderMessageProcessor(c.logger, c.retry)
+	return c.consume(ctx, r, batchOptions{size: 1}, perMessage(func(ctx context.Context, data []byte) error {
+		return processor.ProcessMessage(ctx, data, sink)
+	}))
+}
+
+// ConsumeStatusEvents читает события смены статуса. Готовность сервиса (LastFetch)
+// отслеживается только по заказам.
+func (c *orderConsumer) ConsumeStatusEvents(ctx context.Context, r source.Reader, sink StatusEventSink) error {
+	defer c.closeReader(r)
+
+	c.logger.Info("Status events consumer started", slog.Int("workers", c.workers))
+
+	processor := NewStatusEventProcessor(c.logger, c.retry)
+	return c.consume(ctx, r, batchOptions{size: 1}, perMessage(func(ctx context.Context, data []byte) error {
+		return processor.ProcessMessage(ctx, data, sink)
+	}))
+}
+
+// perMessage обрабатывает пачку по одному сообщению. После остановки оставшиеся
+// сообщения не обрабатываются.
+func perMessage(process func(ctx context.Context, data []byte) error) processFunc {
+	return func(ctx context.Context, msgs []source.Message) []error {
+		errs := make([]error, len(msgs))
+		for i, m := range msgs {
+			if ctx.Err() != nil {
+				errs[i] = ctx.Err()
+				continue
+			}
+			errs[i] = process(contextWithMessage(ctx, m), m.Value)
 		}
+		return errs
+	}
+}
+
+// readerStats - reader, отдающий статистику обращений к брокеру
+type readerStats interface {
+	Stats() kafka.ReaderStats
+}
+
+func (c *orderConsumer) closeReader(r source.Reader) {
+	if err := r.Close(); err != nil {
+		c.logger.Error("Failed to close message source", slog.String("error", err.Error()))
+	}
+}
+
+// consume читает сообщения до отмены ctx и раздаёт их обработчикам: партиция закреплена
+// за одним обработчиком, так что её сообщения обрабатываются по порядку, а разные
+// партиции - параллельно. Смещение коммитится после обработки сообщения, а значит,
+// и всех более ранних сообщений той же партиции. Отклонённые сообщения отправляются в DLQ.
+func (c *orderConsumer) consume(ctx context.Context, r source.Reader, batch batchOptions, process processFunc) error {
+	queues := make([]chan source.Message, c.workers)
+	done := make(chan source.Message, c.workers*workerQueueSize)
+
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan source.Message, workerQueueSize)
+		wg.Add(1)
+		go func(queue <-chan source.Message) {
+			defer wg.Done()
+			c.work(ctx, queue, done, batch, process)
+		}(queues[i])
+	}
+
+	committed := make(chan struct{})
+	go func() {
+		defer close(committed)
+		c.commitLoop(r, done)
 	}()
 
-	c.logger.Info("Kafka consumer started",
-		slog.String("topic", topic),
-		slog.String("groupID", groupID),
-		slog.Any("brokers", brokers))
+	c.fetch(ctx, r, queues)
 
-	processor := NewOrderMessageProcessor(c.logger)
+	// Дожидаемся обработчиков и коммита уже обработанных сообщений
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+	close(done)
+	<-committed
 
+	c.logger.Info("Consumer stopped")
+	return nil
+}
+
+// fetch читает сообщения до отмены ctx и кладёт их в очередь обработчика партиции
+func (c *orderConsumer) fetch(ctx context.Context, r source.Reader, queues []chan source.Message) {
 	for {
+		// Fetch не коммитит смещение: коммит делается только после обработки
+		m, err := r.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Error("Error reading message", slog.String("error", err.Error()))
+			// Пауза, чтобы недоступный источник не крутил цикл вхолостую
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(fetchErrorBackoff):
+			}
+			continue
+		}
+
+		metrics.KafkaMessagesConsumed.WithLabelValues(m.Topic, strconv.Itoa(m.Partition)).Inc()
+
 		select {
+		case queues[m.Partition%len(queues)] <- m:
 		case <-ctx.Done():
-			c.logger.Info("Kafka consumer stopped")
-			return nil
-		default:
-			m, err := r.ReadMessage(ctx)
-			if err != nil {
-				if errors.Is(err, context.Canceled) {
-					return nil
-				}
-				c.logger.Error("Error reading Kafka message", slog.String("error", err.Error()))
+			return
+		}
+	}
+}
+
+// work обрабатывает очередь одного обработчика пачками и передаёт обработанные сообщения на коммит
+func (c *orderConsumer) work(ctx context.Context, queue <-chan source.Message, done chan<- source.Message, batch batchOptions, process processFunc) {
+	stopped := false
+	for {
+		msgs, ok := nextBatch(queue, batch)
+		if !ok {
+			return
+		}
+		// После остановки остаток очереди не обрабатывается и не коммитится:
+		// сообщения будут перечитаны после перезапуска
+		if stopped || ctx.Err() != nil {
+			stopped = true
+			continue
+		}
+
+		errs := process(ctx, msgs)
+		for i, m := range msgs {
+			if stopped || !c.settle(ctx, m, errs[i]) {
+				stopped = true
 				continue
 			}
+			done <- m
+		}
+	}
+}
+
+// nextBatch ждёт первое сообщение, а затем добирает пачку до size сообщений, но не дольше timeout.
+// Возвращает false, когда очередь закрыта и пуста.
+func nextBatch(queue <-chan source.Message, batch batchOptions) ([]source.Message, bool) {
+	m, ok := <-queue
+	if !ok {
+		return nil, false
+	}
+	msgs := []source.Message{m}
+	if batch.size <= 1 {
+		return msgs, true
+	}
 
-			if err := processor.ProcessMessage(ctx, m.Value, repo); err != nil {
-				c.logger.Error("Failed to process message", slog.String("error", err.Error()))
-				// Пропускаем сообщение, если не удалось его обработать
+	timer := time.NewTimer(batch.timeout)
+	defer timer.Stop()
+	for len(msgs) < batch.size {
+		select {
+		case m, ok := <-queue:
+			if !ok {
+				return msgs, true
 			}
+			msgs = append(msgs, m)
+		case <-timer.C:
+			return msgs, true
+		}
+	}
+	return msgs, true
+}
+
+// settle разбирается с результатом обработки сообщения. Возвращает false, если обработка
+// прервана остановкой и сообщение нельзя коммитить.
+func (c *orderConsumer) settle(ctx context.Context, m source.Message, err error) bool {
+	if err == nil {
+		return true
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+
+	metrics.KafkaMessagesFailed.WithLabelValues(m.Topic, strconv.Itoa(m.Partition)).Inc()
+	c.logger.Error("Failed to process message",
+		slog.String("error", err.Error()),
+		slog.String("topic", m.Topic),
+		slog.Int("partition", m.Partition),
+		slog.Int64("offset", m.Offset))
+
+	var rejectErr *RejectError
+	if errors.As(err, &rejectErr) && c.shouldDeadLetter(rejectErr) {
+		if err := c.deadLetter(ctx, m, rejectErr); err != nil {
+			return false
+		}
+	}
+	return true
+}
 
-			if err := r.CommitMessages(ctx, m); err != nil {
-				c.logger.Error("Failed to commit Kafka message", slog.String("error", err.Error()))
+// commitLoop коммитит обработанные сообщения, пока не закроется done. Всё, что успело
+// накопиться, коммитится одним запросом: по последнему смещению каждой партиции.
+func (c *orderConsumer) commitLoop(r source.Reader, done <-chan source.Message) {
+	for m := range done {
+		latest := map[int]source.Message{m.Partition: m}
+	drain:
+		for {
+			select {
+			case m, ok := <-done:
+				if !ok {
+					break drain
+				}
+				if prev, seen := latest[m.Partition]; !seen || m.Offset > prev.Offset {
+					latest[m.Partition] = m
+				}
+			default:
+				break drain
+			}
+		}
+		c.commit(r, latest)
+	}
+}
+
+// commit не зависит от контекста consumer'а, чтобы при остановке закоммитить уже обработанное
+func (c *orderConsumer) commit(r source.Reader, latest map[int]source.Message) {
+	msgs := make([]source.Message, 0, len(latest))
+	for _, m := range latest {
+		msgs = append(msgs, m)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commitTimeout)
+	defer cancel()
+	if err := r.Commit(ctx, msgs...); err != nil {
+		metrics.KafkaCommitErrors.WithLabelValues(msgs[0].Topic).Inc()
+		c.logger.Error("Failed to commit message", slog.String("error", err.Error()))
+	}
+}
+
+func (c *orderConsumer) LastFetch() time.Time {
+	ns := c.lastFetch.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// trackFetches обновляет LastFetch по статистике reader'а. Fetch-запросы идут и
+// при пустом топике, поэтому простой топика не делает consumer неготовым.
+func (c *orderConsumer) trackFetches(ctx context.Context, r readerStats) {
+	ticker := time.NewTicker(fetchTrackInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if stats := r.Stats(); stats.Fetches > 0 {
+				c.lastFetch.Store(time.Now().UnixNano())
 			}
 		}
 	}
 }
 
-func (p *orderMessageProcessor) ProcessMessage(ctx context.Context, data []byte, repo repository.Repository) error {
+// shouldDeadLetter решает, отправлять ли отклонённое сообщение в DLQ.
+// Невалидные сообщения отправляются всегда, а несохранённые заказы - согласно retry.fallback.
+func (c *orderConsumer) shouldDeadLetter(cause *RejectError) bool {
+	switch cause.Reason {
+	case ReasonPersistFailed, ReasonRetriesExhausted:
+		if c.retry.Fallback == FallbackSkip {
+			c.logger.Warn("Skipping message that could not be saved", slog.String("reason", cause.Reason))
+			return false
+		}
+	}
+	return true
+}
+
+// deadLetter отправляет отклонённое сообщение в DLQ, повторяя попытки до успеха
+// или отмены контекста, чтобы не закоммитить сообщение, которое никуда не попало
+func (c *orderConsumer) deadLetter(ctx context.Context, m source.Message, cause *RejectError) error {
+	if c.dlq == nil {
+		c.logger.Warn("DLQ is not configured, dropping rejected message",
+			slog.String("reason", cause.Reason),
+			slog.Int("partition", m.Partition),
+			slog.Int64("offset", m.Offset))
+		return nil
+	}
+
+	for {
+		err := c.dlq.Publish(ctx, m, cause)
+		if err == nil {
+			c.logger.Info("Message sent to DLQ",
+				slog.String("reason", cause.Reason),
+				slog.Int("partition", m.Partition),
+				slog.Int64("offset", m.Offset))
+			return nil
+		}
+
+		c.logger.Error("Failed to send message to DLQ, retrying...", slog.String("error", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(dlqRetryInterval):
+		}
+	}
+}
+
+func (p *orderMessageProcessor) ProcessMessage(ctx context.Context, data []byte, sink OrderSink) error {
+	order, err := p.decode(data)
+	if err != nil {
+		return err
+	}
+	return p.save(ctx, order, sink)
+}
+
+// decode разбирает и валидирует заказ из сообщения
+func (p *orderMessageProcessor) decode(data []byte) (*dto.OrderDTO, error) {
 	var order dto.OrderDTO
 	if err := json.Unmarshal(data, &order); err != nil {
-		return errors.New("invalid message format: " + err.Error())
+		return nil, &RejectError{
+			Reason: ReasonInvalidFormat,
+			Err:    errors.New("invalid message format: " + err.Error()),
+		}
 	}
 
 	// Validate order data
@@ -98,36 +448,87 @@ func (p *orderMessageProcessor) ProcessMessage(ctx context.Context, data []byte,
 		p.logger.Error("Order validation failed",
 			slog.String("order_uid", order.OrderUID),
 			slog.String("validation_error", err.Error()))
-		return errors.New("order validation failed: " + err.Error())
+		return nil, &RejectError{
+			Reason: ReasonValidationFailed,
+			Err:    fmt.Errorf("order validation failed: %w", err),
+		}
 	}
+	return &order, nil
+}
 
+// save сохраняет заказ, повторяя временные ошибки; уже сохранённый заказ пропускается
+func (p *orderMessageProcessor) save(ctx context.Context, order *dto.OrderDTO, sink OrderSink) error {
 	// Retry loop for database operations
-	for {
-		_, err := repo.CreateOrder(ctx, &order)
-		if err != nil {
-			var pgErr *pq.Error
-			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-				p.logger.Info("Order already exists, skipping",
-					slog.String("order_uid", order.OrderUID),
-					slog.String("table", pgErr.Table),
-					slog.String("constraint", pgErr.Constraint))
-				return nil
-			}
-
-			p.logger.Error("Failed to save order, retrying...",
+	err := saveWithRetry(ctx, p.retry, p.logger.With(slog.String("order_uid", order.OrderUID)), "order", func() error {
+		_, err := sink.CreateOrder(ctx, order)
+		if errors.Is(err, repository.ErrOrderExists) {
+			p.logger.Info("Order already exists, skipping",
 				slog.String("order_uid", order.OrderUID),
 				slog.String("error", err.Error()))
-
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(3 * time.Second):
-				continue
-			}
+			return nil
 		}
-		break
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
 	p.logger.Info("Order processed successfully", slog.String("order_uid", order.OrderUID))
 	return nil
 }
+
+// retryable сообщает, имеет ли смысл повторить сохранение. Хранилище помечает постоянные
+// ошибки repository.ErrPermanent, поэтому обработка сообщений не зависит от драйвера БД.
+func retryable(err error) bool {
+	return !errors.Is(err, repository.ErrPermanent) && !errors.Is(err, repository.ErrOrderExists)
+}
+
+// saveWithRetry вызывает save, повторяя временные ошибки согласно retry. Постоянная ошибка
+// и исчерпание попыток возвращаются как RejectError; subject - что сохраняется, для логов и ошибок.
+func saveWithRetry(ctx context.Context, retry RetryPolicy, logger *slog.Logger, subject string, save func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := save()
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if !retryable(err) {
+			logger.Error("Failed to save "+subject+", permanent error",
+				slog.String("error", err.Error()))
+			return &RejectError{
+				Reason: ReasonPersistFailed,
+				Err:    fmt.Errorf("failed to save %s: %w", subject, err),
+			}
+		}
+
+		if retry.Exhausted(attempt) {
+			logger.Error("Failed to save "+subject+", retries exhausted",
+				slog.Int("attempts", attempt),
+				slog.String("error", err.Error()))
+			return &RejectError{
+				Reason: ReasonRetriesExhausted,
+				Err:    fmt.Errorf("failed to save %s after %d attempts: %w", subject, attempt, err),
+			}
+		}
+
+		if meta, ok := messageFromContext(ctx); ok {
+			metrics.KafkaMessagesRetried.WithLabelValues(meta.topic, meta.partition).Inc()
+		}
+
+		backoff := retry.Backoff(attempt)
+		logger.Warn("Failed to save "+subject+", retrying...",
+			slog.Int("attempt", attempt),
+			slog.Duration("backoff", backoff),
+			slog.String("error", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
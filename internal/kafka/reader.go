@@ -0,0 +1,66 @@
+package kafka
+
+import (
+	"L0/internal/source"
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// reader - источник сообщений из топика Kafka в группе потребителей
+type reader struct {
+	r *kafka.Reader
+}
+
+// NewReader создает источник, читающий topic в группе groupID. Смещения коммитятся только явно.
+func NewReader(brokers []string, topic, groupID string) source.Reader {
+	return &reader{
+		r: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:        brokers,
+			GroupID:        groupID,
+			Topic:          topic,
+			MinBytes:       10e3, // 10KB
+			MaxBytes:       10e6, // 10MB
+			CommitInterval: 0,
+		}),
+	}
+}
+
+func (r *reader) Fetch(ctx context.Context) (source.Message, error) {
+	// FetchMessage не коммитит смещение: коммит делается только после обработки
+	m, err := r.r.FetchMessage(ctx)
+	if err != nil {
+		return source.Message{}, err
+	}
+
+	headers := make([]source.Header, 0, len(m.Headers))
+	for _, h := range m.Headers {
+		headers = append(headers, source.Header{Key: h.Key, Value: h.Value})
+	}
+	return source.Message{
+		Topic:     m.Topic,
+		Partition: m.Partition,
+		Offset:    m.Offset,
+		Key:       m.Key,
+		Value:     m.Value,
+		Headers:   headers,
+		Time:      m.Time,
+	}, nil
+}
+
+func (r *reader) Commit(ctx context.Context, msgs ...source.Message) error {
+	commits := make([]kafka.Message, 0, len(msgs))
+	for _, m := range msgs {
+		commits = append(commits, kafka.Message{Topic: m.Topic, Partition: m.Partition, Offset: m.Offset})
+	}
+	return r.r.CommitMessages(ctx, commits...)
+}
+
+// Stats нужна consumer'у для LastFetch
+func (r *reader) Stats() kafka.ReaderStats {
+	return r.r.Stats()
+}
+
+func (r *reader) Close() error {
+	return r.r.Close()
+}
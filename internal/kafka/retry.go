@@ -0,0 +1,63 @@
+package kafka
+
+import (
+	"L0/internal/config"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Действия с сообщением, которое не удалось сохранить после всех попыток
+const (
+	FallbackDLQ  = "dlq"
+	FallbackSkip = "skip"
+)
+
+// RetryPolicy описывает повторные попытки сохранения заказа
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter - доля случайного отклонения паузы, от 0 до 1
+	Jitter float64
+	// Fallback - куда деть сообщение после исчерпания попыток или постоянной ошибки
+	Fallback string
+}
+
+// NewRetryPolicy создает политику из секции kafka.retry конфига
+func NewRetryPolicy(cfg config.KafkaRetry) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    cfg.MaxAttempts,
+		InitialBackoff: cfg.InitialBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+		Multiplier:     cfg.Multiplier,
+		Jitter:         cfg.Jitter,
+		Fallback:       cfg.Fallback,
+	}
+}
+
+// Backoff возвращает паузу перед следующей попыткой после неудачной попытки номер attempt (с 1)
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		backoff *= 1 + jitter*(2*rand.Float64()-1)
+	}
+
+	return time.Duration(backoff)
+}
+
+// Exhausted сообщает, что попытка номер attempt была последней
+func (p RetryPolicy) Exhausted(attempt int) bool {
+	return p.MaxAttempts > 0 && attempt >= p.MaxAttempts
+}
@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"L0/internal/kafka/dto"
+	"L0/internal/models"
+	"L0/internal/validation"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+type statusEventProcessor struct {
+	logger    *slog.Logger
+	retry     RetryPolicy
+	validator *validation.StatusEventValidator
+}
+
+// NewStatusEventProcessor создает процессор событий смены статуса
+func NewStatusEventProcessor(logger *slog.Logger, retry RetryPolicy) StatusEventProcessor {
+	return &statusEventProcessor{
+		logger:    logger,
+		retry:     retry,
+		validator: validation.NewStatusEventValidator(),
+	}
+}
+
+func (p *statusEventProcessor) ProcessMessage(ctx context.Context, data []byte, sink StatusEventSink) error {
+	var event dto.StatusEventDTO
+	if err := json.Unmarshal(data, &event); err != nil {
+		return &RejectError{
+			Reason: ReasonInvalidFormat,
+			Err:    errors.New("invalid message format: " + err.Error()),
+		}
+	}
+
+	if err := p.validator.ValidateStatusEvent(&event); err != nil {
+		p.logger.Error("Status event validation failed",
+			slog.String("event_id", event.EventID),
+			slog.String("validation_error", err.Error()))
+		return &RejectError{
+			Reason: ReasonValidationFailed,
+			Err:    fmt.Errorf("status event validation failed: %w", err),
+		}
+	}
+
+	logger := p.logger.With(
+		slog.String("event_id", event.EventID),
+		slog.String("order_uid", event.OrderUID))
+
+	var illegal error
+	err := saveWithRetry(ctx, p.retry, logger, "status event", func() error {
+		err := sink.ApplyStatusEvent(ctx, &event)
+		if errors.Is(err, models.ErrIllegalTransition) {
+			// Повтор не поможет: статус заказа уже не позволяет этот переход
+			illegal = err
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if illegal != nil {
+		logger.Warn("Status event rejected", slog.String("error", illegal.Error()))
+		return &RejectError{
+			Reason: ReasonIllegalTransition,
+			Err:    illegal,
+		}
+	}
+
+	logger.Info("Status event processed", slog.String("status", event.Status))
+	return nil
+}
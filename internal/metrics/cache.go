@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"L0/internal/cache"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cacheHitsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cache", "hits_total"),
+		"Number of cache lookups that found a value.", nil, nil)
+	cacheMissesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cache", "misses_total"),
+		"Number of cache lookups that found nothing.", nil, nil)
+	cacheEvictionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cache", "evictions_total"),
+		"Number of entries removed from the cache by expiration or invalidation.", nil, nil)
+	cacheEntriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cache", "entries"),
+		"Number of entries currently held in the cache.", nil, nil)
+)
+
+// cacheCollector читает статистику кэша один раз за сбор, поэтому все серии относятся к одному моменту
+type cacheCollector struct {
+	provider cache.StatsProvider
+}
+
+// RegisterCache экспортирует статистику кэша. Значения читаются в момент сбора метрик.
+func RegisterCache(provider cache.StatsProvider) {
+	Registry.MustRegister(&cacheCollector{provider: provider})
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHitsDesc
+	ch <- cacheMissesDesc
+	ch <- cacheEvictionsDesc
+	ch <- cacheEntriesDesc
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.provider.Stats()
+	ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(cacheEvictionsDesc, prometheus.CounterValue, float64(stats.Evictions))
+	if stats.Items != cache.ItemsUnknown {
+		ch <- prometheus.MustNewConstMetric(cacheEntriesDesc, prometheus.GaugeValue, float64(stats.Items))
+	}
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace - общий префикс всех метрик сервиса.
+// Имена метрик являются частью контракта с дашбордами, см. docs/metrics.md
+const namespace = "l0"
+
+// Registry содержит все метрики сервиса, включая метрики Go runtime и процесса
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	HTTPRequestsTotal = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by method, chi route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and chi route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	KafkaMessagesConsumed = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_consumed_total",
+		Help:      "Number of messages read from Kafka.",
+	}, []string{"topic", "partition"})
+
+	KafkaMessagesFailed = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_failed_total",
+		Help:      "Number of Kafka messages that could not be processed.",
+	}, []string{"topic", "partition"})
+
+	KafkaMessagesRetried = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_retried_total",
+		Help:      "Number of retried attempts to persist an order from a Kafka message.",
+	}, []string{"topic", "partition"})
+
+	KafkaCommitErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "commit_errors_total",
+		Help:      "Number of failed Kafka offset commits.",
+	}, []string{"topic"})
+
+	KafkaBatchFallbacks = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "batch_fallbacks_total",
+		Help:      "Number of order batches that failed to save and were processed message by message.",
+	}, []string{"topic"})
+
+	OutboxEventsPublished = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "events_published_total",
+		Help:      "Number of outbox events published to Kafka.",
+	})
+
+	OutboxPublishErrors = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "publish_errors_total",
+		Help:      "Number of failed attempts to publish a batch of outbox events.",
+	})
+
+	OutboxPendingEvents = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "pending_events",
+		Help:      "Number of outbox events not yet published.",
+	})
+
+	OutboxOldestEventAge = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "oldest_event_age_seconds",
+		Help:      "Age of the oldest unpublished outbox event, 0 when the outbox is empty.",
+	})
+
+	DBQueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of postgres.Storage operations.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler отдаёт метрики в формате Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -16,7 +16,8 @@ type Order struct {
 	ShardKey          string `gorm:"column:shardkey;type:text;not null"`
 	SmID              uint64
 	DateCreated       *time.Time
-	OofShard          string `gorm:"type:text"`
+	OofShard          string      `gorm:"type:text"`
+	Status            OrderStatus `gorm:"type:text;not null;default:created"`
 
 	DeliveryID uint64
 	PaymentID  uint64
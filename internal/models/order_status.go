@@ -0,0 +1,102 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// OrderStatus - состояние заказа
+type OrderStatus string
+
+const (
+	StatusCreated    OrderStatus = "created"
+	StatusPaid       OrderStatus = "paid"
+	StatusAssembling OrderStatus = "assembling"
+	StatusShipped    OrderStatus = "shipped"
+	StatusDelivered  OrderStatus = "delivered"
+	StatusCancelled  OrderStatus = "cancelled"
+	StatusReturned   OrderStatus = "returned"
+)
+
+var (
+	// ErrUnknownStatus возвращается для статуса, которого нет в модели
+	ErrUnknownStatus = errors.New("unknown order status")
+	// ErrIllegalTransition возвращается при переходе, не разрешённом orderTransitions
+	ErrIllegalTransition = errors.New("illegal order status transition")
+)
+
+// orderTransitions - допустимые переходы между статусами.
+// Отменить можно только ещё не отправленный заказ, вернуть - отправленный или доставленный.
+// Из cancelled и returned переходов нет.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	StatusCreated:    {StatusPaid, StatusCancelled},
+	StatusPaid:       {StatusAssembling, StatusCancelled},
+	StatusAssembling: {StatusShipped, StatusCancelled},
+	StatusShipped:    {StatusDelivered, StatusReturned},
+	StatusDelivered:  {StatusReturned},
+	StatusCancelled:  nil,
+	StatusReturned:   nil,
+}
+
+// ParseOrderStatus проверяет, что s - известный статус
+func ParseOrderStatus(s string) (OrderStatus, error) {
+	status := OrderStatus(s)
+	if _, ok := orderTransitions[status]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownStatus, s)
+	}
+	return status, nil
+}
+
+// CanTransitionTo сообщает, можно ли перевести заказ из статуса s в to
+func (s OrderStatus) CanTransitionTo(to OrderStatus) bool {
+	for _, next := range orderTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition возвращает ErrIllegalTransition, если переход from -> to не разрешён
+func ValidateTransition(from, to OrderStatus) error {
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+	}
+	return nil
+}
+
+// OrderStatusChange - запись истории смены статусов заказа
+type OrderStatusChange struct {
+	ID         uint64      `gorm:"primaryKey;autoIncrement"`
+	OrderID    uint64      `gorm:"not null;index"`
+	FromStatus OrderStatus `gorm:"type:text;not null"`
+	ToStatus   OrderStatus `gorm:"type:text;not null"`
+	Reason     string      `gorm:"type:text"`
+	ChangedAt  time.Time   `gorm:"not null"`
+	// EventID - идентификатор события из Kafka, nil для смены статуса через API
+	EventID *string `gorm:"type:text;unique"`
+}
+
+func (OrderStatusChange) TableName() string {
+	return "order_status_history"
+}
+
+// PendingStatusEvent - событие смены статуса, пришедшее раньше самого заказа.
+// Применяется, когда заказ будет сохранён.
+type PendingStatusEvent struct {
+	EventID    string      `gorm:"primaryKey;type:text"`
+	OrderUID   string      `gorm:"type:text;not null;index"`
+	Status     OrderStatus `gorm:"type:text;not null"`
+	Reason     string      `gorm:"type:text"`
+	OccurredAt time.Time   `gorm:"not null"`
+	ReceivedAt time.Time   `gorm:"autoCreateTime"`
+}
+
+// SkippedStatusEvent - событие, которое не изменило статус: заказ уже был в статусе события.
+// Запоминается, чтобы повторная доставка после следующей смены статуса не считалась недопустимым переходом.
+type SkippedStatusEvent struct {
+	EventID   string    `gorm:"primaryKey;type:text"`
+	OrderUID  string    `gorm:"type:text;not null"`
+	SkippedAt time.Time `gorm:"autoCreateTime"`
+}
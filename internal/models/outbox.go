@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// OutboxEvent - событие, записанное в той же транзакции, что и изменение данных.
+// Удаляется после публикации в Kafka.
+type OutboxEvent struct {
+	ID          uint64 `gorm:"primaryKey;autoIncrement"`
+	AggregateID string `gorm:"type:text;not null"`
+	EventType   string `gorm:"type:text;not null"`
+	Payload     []byte `gorm:"type:jsonb;not null"`
+	CreatedAt   time.Time
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
@@ -0,0 +1,19 @@
+package repository
+
+import "errors"
+
+var (
+	// ErrOrderExists возвращается при попытке сохранить заказ с уже существующим order_uid
+	ErrOrderExists = errors.New("order already exists")
+	// ErrTrackNumberTaken возвращается, если track_number нового заказа уже занят другим заказом
+	ErrTrackNumberTaken = errors.New("track number is already used by another order")
+	// ErrInvalidCursor возвращается, если курсор пагинации не удалось разобрать
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrStatusConflict возвращается, если статус заказа изменился с момента его чтения
+	ErrStatusConflict = errors.New("order status was changed concurrently")
+	// ErrEventProcessed возвращается при повторном применении события смены статуса
+	ErrEventProcessed = errors.New("status event already processed")
+	// ErrPermanent оборачивает ошибки хранилища, которые не исчезнут при повторе:
+	// нарушения ограничений, некорректные данные. Остальные ошибки считаются временными.
+	ErrPermanent = errors.New("permanent storage error")
+)
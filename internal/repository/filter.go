@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"L0/internal/kafka/dto"
+	"encoding/base64"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultPageSize = 20
+	// MaxPageSize - максимальный размер страницы для GET /orders
+	MaxPageSize = 100
+	// MaxBatchSize - максимальный размер страницы внутренних выборок, например прогрева кэша
+	MaxBatchSize = 5000
+)
+
+// OrderFilter описывает фильтры и параметры пагинации для списка заказов
+type OrderFilter struct {
+	CustomerID      string
+	DeliveryService string
+	Entry           string
+	Locale          string
+	DateFrom        *time.Time
+	DateTo          *time.Time
+	// UpdatedAfter отбирает заказы, изменённые после указанного момента
+	UpdatedAfter *time.Time
+
+	Cursor string
+	Limit  int
+	// Batch поднимает ограничение размера страницы с MaxPageSize до MaxBatchSize.
+	// Для внутренних выборок; из параметров HTTP-запроса не заполняется.
+	Batch bool
+}
+
+// OrderPage - страница заказов с курсором на следующую страницу
+type OrderPage struct {
+	Orders     []dto.OrderDTO `json:"orders"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// EncodeCursor кодирует ID последней записи страницы в непрозрачный токен
+func EncodeCursor(id uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(id, 10)))
+}
+
+// DecodeCursor разбирает токен, полученный из EncodeCursor
+func DecodeCursor(cursor string) (uint64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil || id == 0 {
+		return 0, ErrInvalidCursor
+	}
+
+	return id, nil
+}
+
+// PageSize возвращает лимит страницы с учётом значения по умолчанию и верхней границы
+func (f OrderFilter) PageSize() int {
+	maxSize := MaxPageSize
+	if f.Batch {
+		maxSize = MaxBatchSize
+	}
+
+	switch {
+	case f.Limit <= 0:
+		return DefaultPageSize
+	case f.Limit > maxSize:
+		return maxSize
+	default:
+		return f.Limit
+	}
+}
@@ -3,10 +3,29 @@ package repository
 import (
 	"L0/internal/kafka/dto"
 	"context"
+	"time"
 )
 
 type Repository interface {
 	CreateOrder(ctx context.Context, o *dto.OrderDTO) (*dto.OrderDTO, error)
+	// CreateOrders сохраняет заказы одной транзакцией: либо все, либо ни одного
+	CreateOrders(ctx context.Context, orders []*dto.OrderDTO) ([]*dto.OrderDTO, error)
 	GetAllOrders(ctx context.Context) ([]dto.OrderDTO, error)
 	GetOrderByUID(ctx context.Context, orderUID string) (*dto.OrderDTO, error)
+	ListOrders(ctx context.Context, filter OrderFilter) (*OrderPage, error)
+	// UpdateOrderStatus меняет статус заказа и записывает смену в историю
+	UpdateOrderStatus(ctx context.Context, change StatusChange) error
+	// StatusEventProcessed сообщает, применено или пропущено ли уже событие eventID
+	StatusEventProcessed(ctx context.Context, eventID string) (bool, error)
+	// MarkStatusEventSkipped запоминает событие, которое не изменило статус заказа
+	MarkStatusEventSkipped(ctx context.Context, event StatusEvent) error
+	// SavePendingStatusEvent откладывает событие для ещё не полученного заказа; повторная запись игнорируется
+	SavePendingStatusEvent(ctx context.Context, event StatusEvent) error
+	// PendingStatusEvents возвращает отложенные события заказа в порядке occurred_at
+	PendingStatusEvents(ctx context.Context, orderUID string) ([]StatusEvent, error)
+	DeletePendingStatusEvent(ctx context.Context, eventID string) error
+	// OrdersWithPendingStatusEvents возвращает до limit order_uid уже сохранённых заказов с отложенными событиями
+	OrdersWithPendingStatusEvents(ctx context.Context, limit int) ([]string, error)
+	// PurgePendingStatusEvents удаляет отложенные события, полученные раньше receivedBefore, и возвращает их число
+	PurgePendingStatusEvents(ctx context.Context, receivedBefore time.Time) (int64, error)
 }
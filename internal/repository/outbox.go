@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxEvent - неопубликованное событие из outbox
+type OutboxEvent struct {
+	ID uint64
+	// AggregateID - order_uid, используется как ключ сообщения Kafka
+	AggregateID string
+	EventType   string
+	Payload     []byte
+	CreatedAt   time.Time
+}
+
+// Outbox - события, записанные вместе с заказами и ожидающие публикации
+type Outbox interface {
+	// PublishOutbox берёт до limit самых старых неопубликованных событий, передаёт их в publish
+	// и отмечает опубликованными, если publish вернул nil. Возвращает число опубликованных событий.
+	// Пока события обрабатывает одна реплика, вызовы из других возвращают 0.
+	PublishOutbox(ctx context.Context, limit int, publish func(ctx context.Context, events []OutboxEvent) error) (int, error)
+	// OutboxLag возвращает число неопубликованных событий и время создания самого старого из них
+	OutboxLag(ctx context.Context) (int64, time.Time, error)
+}
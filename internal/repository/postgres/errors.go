@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"L0/internal/repository"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+const codeUniqueViolation = "23505"
+
+// Уникальные ограничения таблицы orders (имена по умолчанию из 000004_create_orders_table)
+const (
+	constraintOrderUID    = "orders_order_uid_key"
+	constraintTrackNumber = "orders_track_number_key"
+)
+
+// IsUniqueViolation сообщает, что ошибка вызвана нарушением уникального ограничения.
+// Проверяются ошибки обоих драйверов: pgx (через gorm) и lib/pq.
+func IsUniqueViolation(err error) bool {
+	if errors.Is(err, repository.ErrOrderExists) {
+		return true
+	}
+	return errorCode(err) == codeUniqueViolation
+}
+
+// classifyCreateError переводит нарушения уникальности при сохранении заказа в ошибки репозитория.
+// ErrOrderExists означает только повтор order_uid: занятый другим заказом track_number - это
+// другой заказ, его нельзя считать дубликатом.
+func classifyCreateError(err error) error {
+	if errorCode(err) == codeUniqueViolation {
+		switch constraintName(err) {
+		case constraintOrderUID:
+			return fmt.Errorf("%w: %w", repository.ErrOrderExists, err)
+		case constraintTrackNumber:
+			err = fmt.Errorf("%w: %w", repository.ErrTrackNumberTaken, err)
+		}
+	}
+	return markPermanent(err)
+}
+
+// IsRetryable сообщает, имеет ли смысл повторить операцию, завершившуюся ошибкой err.
+//
+// Повторяемыми считаются ошибки соединения (класс 08), конфликты сериализации (40001),
+// взаимоблокировки (40P01), нехватка ресурсов (класс 53), остановка сервера (57P01-57P03),
+// недоступная блокировка (55P03), а также ошибки без кода SQLSTATE - обычно это
+// сетевые сбои, таймауты и обрыв соединения. Остальные ошибки Postgres
+// (нарушения ограничений, некорректные данные) постоянные.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if code := errorCode(err); code != "" {
+		switch {
+		case strings.HasPrefix(code, "08"),
+			strings.HasPrefix(code, "53"),
+			code == "40001",
+			code == "40P01",
+			code == "55P03",
+			code == "57P01",
+			code == "57P02",
+			code == "57P03":
+			return true
+		default:
+			return false
+		}
+	}
+
+	// Ошибки без SQLSTATE: сетевые сбои, таймауты, обрыв соединения и т.п.
+	return !errors.Is(err, repository.ErrOrderExists)
+}
+
+// markPermanent оборачивает постоянную ошибку в repository.ErrPermanent, чтобы вызывающий код
+// решал, повторять ли операцию, не разбирая ошибки драйвера
+func markPermanent(err error) error {
+	if err == nil || IsRetryable(err) || errors.Is(err, repository.ErrOrderExists) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", repository.ErrPermanent, err)
+}
+
+// errorCode возвращает SQLSTATE ошибки Postgres или пустую строку
+func errorCode(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+
+	return ""
+}
+
+// constraintName возвращает имя нарушенного ограничения или пустую строку
+func constraintName(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.ConstraintName
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Constraint
+	}
+
+	return ""
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_orders_date_created;
+DROP INDEX IF EXISTS idx_orders_customer_id;
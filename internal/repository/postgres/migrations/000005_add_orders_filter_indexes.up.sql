@@ -0,0 +1,2 @@
+CREATE INDEX idx_orders_customer_id ON orders (customer_id);
+CREATE INDEX idx_orders_date_created ON orders (date_created);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS order_status_history;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_status;
+ALTER TABLE orders DROP COLUMN IF EXISTS status;
@@ -0,0 +1,17 @@
+ALTER TABLE orders
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'created',
+    ADD CONSTRAINT chk_orders_status CHECK (
+        status IN ('created', 'paid', 'assembling', 'shipped', 'delivered', 'cancelled', 'returned')
+    );
+
+CREATE TABLE order_status_history (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    order_id BIGINT NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    reason TEXT,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_order_status_history_order FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_order_status_history_order_id ON order_status_history (order_id, changed_at);
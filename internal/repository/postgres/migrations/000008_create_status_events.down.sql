@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS skipped_status_events;
+DROP TABLE IF EXISTS pending_status_events;
+
+ALTER TABLE order_status_history DROP CONSTRAINT IF EXISTS uq_order_status_history_event_id;
+ALTER TABLE order_status_history DROP COLUMN IF EXISTS event_id;
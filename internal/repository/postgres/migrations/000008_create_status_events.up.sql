@@ -0,0 +1,22 @@
+ALTER TABLE order_status_history
+    ADD COLUMN event_id TEXT,
+    ADD CONSTRAINT uq_order_status_history_event_id UNIQUE (event_id);
+
+CREATE TABLE pending_status_events (
+    event_id TEXT PRIMARY KEY,
+    order_uid TEXT NOT NULL,
+    status TEXT NOT NULL,
+    reason TEXT,
+    occurred_at TIMESTAMP NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_pending_status_events_order_uid ON pending_status_events (order_uid, occurred_at);
+CREATE INDEX idx_pending_status_events_received_at ON pending_status_events (received_at);
+
+-- События, которые не изменили статус: заказ уже был в статусе события
+CREATE TABLE skipped_status_events (
+    event_id TEXT PRIMARY KEY,
+    order_uid TEXT NOT NULL,
+    skipped_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
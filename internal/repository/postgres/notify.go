@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Операции, о которых сообщает Notifier
+const (
+	OpCreated       = "created"
+	OpStatusChanged = "status_changed"
+)
+
+// listenerPingInterval - как часто проверять соединение listener'а, если уведомлений нет
+const listenerPingInterval = 90 * time.Second
+
+// OrderChange - полезная нагрузка NOTIFY об изменении заказа
+type OrderChange struct {
+	OrderUID string `json:"order_uid"`
+	Op       string `json:"op"`
+	// Origin - идентификатор реплики, сделавшей запись: свои уведомления она пропускает
+	Origin string `json:"origin"`
+}
+
+// Notifier отправляет NOTIFY об изменениях заказов. Уведомление уходит в той же
+// транзакции, что и запись, поэтому доставляется только после коммита.
+type Notifier struct {
+	Channel string
+	Origin  string
+}
+
+func NewNotifier(channel, origin string) *Notifier {
+	return &Notifier{
+		Channel: channel,
+		Origin:  origin,
+	}
+}
+
+// NotifyOrderChanged ставит уведомление в очередь транзакции tx
+func (n *Notifier) NotifyOrderChanged(tx *gorm.DB, orderUID, op string) error {
+	payload, err := json.Marshal(OrderChange{OrderUID: orderUID, Op: op, Origin: n.Origin})
+	if err != nil {
+		return err
+	}
+	if err := tx.Exec("SELECT pg_notify(?, ?)", n.Channel, string(payload)).Error; err != nil {
+		return fmt.Errorf("failed to notify order change: %w", err)
+	}
+	return nil
+}
+
+// ChangeHandler применяет изменения заказов, сделанные другими репликами
+type ChangeHandler interface {
+	OrderChanged(change OrderChange)
+	// Resync вызывается после переподключения: уведомления за время разрыва потеряны
+	Resync()
+}
+
+// Listener подписывается на уведомления Notifier через LISTEN и
+// переподключается при разрыве соединения
+type Listener struct {
+	dsn          string
+	channel      string
+	origin       string
+	minReconnect time.Duration
+	maxReconnect time.Duration
+	logger       *slog.Logger
+}
+
+func NewListener(dsn, channel, origin string, minReconnect, maxReconnect time.Duration, logger *slog.Logger) *Listener {
+	return &Listener{
+		dsn:          dsn,
+		channel:      channel,
+		origin:       origin,
+		minReconnect: minReconnect,
+		maxReconnect: maxReconnect,
+		logger:       logger,
+	}
+}
+
+// Run слушает канал до отмены ctx
+func (l *Listener) Run(ctx context.Context, handler ChangeHandler) error {
+	listener := pq.NewListener(l.dsn, l.minReconnect, l.maxReconnect, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventConnectionAttemptFailed, pq.ListenerEventDisconnected:
+			l.logger.Warn("Order change listener lost connection", slog.Any("error", err))
+		case pq.ListenerEventReconnected:
+			l.logger.Info("Order change listener reconnected")
+		}
+	})
+
+	// Listen блокируется, пока нет соединения; Close при отмене ctx прерывает ожидание
+	stop := context.AfterFunc(ctx, func() { _ = listener.Close() })
+	defer func() {
+		if stop() {
+			_ = listener.Close()
+		}
+	}()
+
+	if err := listener.Listen(l.channel); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to listen on %q: %w", l.channel, err)
+	}
+	l.logger.Info("Listening for order changes", slog.String("channel", l.channel))
+
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n, ok := <-listener.Notify:
+			if !ok {
+				return nil
+			}
+			if n == nil {
+				handler.Resync()
+				continue
+			}
+			l.handle(n.Extra, handler)
+		case <-ping.C:
+			// Ping обнаруживает оборванное соединение, по которому давно ничего не приходило
+			go func() {
+				if err := listener.Ping(); err != nil && !errors.Is(err, pq.ErrChannelNotOpen) {
+					l.logger.Debug("Order change listener ping failed", slog.String("error", err.Error()))
+				}
+			}()
+		}
+	}
+}
+
+func (l *Listener) handle(payload string, handler ChangeHandler) {
+	var change OrderChange
+	if err := json.Unmarshal([]byte(payload), &change); err != nil || change.OrderUID == "" {
+		l.logger.Warn("Invalid order change notification", slog.String("payload", payload))
+		return
+	}
+	if change.Origin == l.origin {
+		return
+	}
+	handler.OrderChanged(change)
+}
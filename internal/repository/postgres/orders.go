@@ -3,101 +3,142 @@ package postgres
 import (
 	"L0/internal/kafka/dto"
 	"L0/internal/models"
+	"L0/internal/repository"
 	"context"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// insertBatchSize ограничивает число строк в одном INSERT, чтобы не упереться в лимит параметров запроса
+const insertBatchSize = 500
+
 func CreateOrder(ctx context.Context, db *gorm.DB, o *dto.OrderDTO) (*models.Order, error) {
-	parsedDateCreated, err := time.Parse(time.RFC3339, o.DateCreated)
+	order, delivery, payment, err := newOrderRows(o)
 	if err != nil {
 		return nil, err
 	}
 
-	var order models.Order
-
 	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// Delivery
-		delivery := models.Delivery{
-			Name:    o.Delivery.Name,
-			Phone:   o.Delivery.Phone,
-			Zip:     o.Delivery.Zip,
-			City:    o.Delivery.City,
-			Address: o.Delivery.Address,
-			Region:  o.Delivery.Region,
-			Email:   o.Delivery.Email,
-		}
 		if err := tx.Create(&delivery).Error; err != nil {
 			return err
 		}
-
-		// Payment
-		payment := models.Payment{
-			Transaction:  o.Payment.Transaction,
-			RequestID:    o.Payment.RequestID,
-			Currency:     o.Payment.Currency,
-			Provider:     o.Payment.Provider,
-			Amount:       o.Payment.Amount,
-			PaymentDT:    o.Payment.PaymentDt,
-			Bank:         o.Payment.Bank,
-			DeliveryCost: o.Payment.DeliveryCost,
-			GoodsTotal:   o.Payment.GoodsTotal,
-			CustomFee:    o.Payment.CustomFee,
-		}
 		if err := tx.Create(&payment).Error; err != nil {
 			return err
 		}
 
-		// Items
-		var items []models.Item
-		for _, it := range o.Items {
-			item := models.Item{
-				ChrtID:      it.ChrtID,
-				TrackNumber: it.TrackNumber,
-				Price:       it.Price,
-				RID:         it.RID,
-				Name:        it.Name,
-				Sale:        it.Sale,
-				Size:        it.Size,
-				TotalPrice:  it.TotalPrice,
-				NmID:        it.NmID,
-				Brand:       it.Brand,
-				Status:      it.Status,
-			}
-			items = append(items, item)
-		}
+		order.DeliveryID = delivery.ID
+		order.PaymentID = payment.ID
+		return tx.Create(&order).Error
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		// Order
-		order = models.Order{
-			OrderUID:          o.OrderUID,
-			TrackNumber:       o.TrackNumber,
-			Entry:             o.Entry,
-			Locale:            o.Locale,
-			InternalSignature: o.InternalSignature,
-			CustomerID:        o.CustomerID,
-			DeliveryService:   o.DeliveryService,
-			ShardKey:          o.Shardkey,
-			SmID:              o.SmID,
-			DateCreated:       &parsedDateCreated,
-			OofShard:          o.OofShard,
-			DeliveryID:        delivery.ID,
-			PaymentID:         payment.ID,
-			Items:             items,
+	return &order, nil
+}
+
+// CreateOrders сохраняет заказы одной транзакцией: доставки, оплаты, заказы и товары
+// пишутся многострочными INSERT. Ошибка любого заказа откатывает все.
+func CreateOrders(ctx context.Context, db *gorm.DB, orders []*dto.OrderDTO) ([]models.Order, error) {
+	created := make([]models.Order, len(orders))
+	deliveries := make([]models.Delivery, len(orders))
+	payments := make([]models.Payment, len(orders))
+	for i, o := range orders {
+		var err error
+		created[i], deliveries[i], payments[i], err = newOrderRows(o)
+		if err != nil {
+			return nil, fmt.Errorf("order %s: %w", o.OrderUID, err)
 		}
+	}
 
-		if err := tx.Create(&order).Error; err != nil {
+	err := db.WithContext(ctx).Session(&gorm.Session{CreateBatchSize: insertBatchSize}).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&deliveries).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&payments).Error; err != nil {
 			return err
 		}
 
-		return nil
+		for i := range created {
+			created[i].DeliveryID = deliveries[i].ID
+			created[i].PaymentID = payments[i].ID
+		}
+		return tx.Create(&created).Error
 	})
-
 	if err != nil {
 		return nil, err
 	}
 
-	return &order, nil
+	return created, nil
+}
+
+// newOrderRows раскладывает заказ по строкам таблиц. DeliveryID и PaymentID заполняются после их вставки.
+func newOrderRows(o *dto.OrderDTO) (models.Order, models.Delivery, models.Payment, error) {
+	parsedDateCreated, err := time.Parse(time.RFC3339, o.DateCreated)
+	if err != nil {
+		return models.Order{}, models.Delivery{}, models.Payment{}, err
+	}
+
+	delivery := models.Delivery{
+		Name:    o.Delivery.Name,
+		Phone:   o.Delivery.Phone,
+		Zip:     o.Delivery.Zip,
+		City:    o.Delivery.City,
+		Address: o.Delivery.Address,
+		Region:  o.Delivery.Region,
+		Email:   o.Delivery.Email,
+	}
+
+	payment := models.Payment{
+		Transaction:  o.Payment.Transaction,
+		RequestID:    o.Payment.RequestID,
+		Currency:     o.Payment.Currency,
+		Provider:     o.Payment.Provider,
+		Amount:       o.Payment.Amount,
+		PaymentDT:    o.Payment.PaymentDt,
+		Bank:         o.Payment.Bank,
+		DeliveryCost: o.Payment.DeliveryCost,
+		GoodsTotal:   o.Payment.GoodsTotal,
+		CustomFee:    o.Payment.CustomFee,
+	}
+
+	var items []models.Item
+	for _, it := range o.Items {
+		items = append(items, models.Item{
+			ChrtID:      it.ChrtID,
+			TrackNumber: it.TrackNumber,
+			Price:       it.Price,
+			RID:         it.RID,
+			Name:        it.Name,
+			Sale:        it.Sale,
+			Size:        it.Size,
+			TotalPrice:  it.TotalPrice,
+			NmID:        it.NmID,
+			Brand:       it.Brand,
+			Status:      it.Status,
+		})
+	}
+
+	order := models.Order{
+		OrderUID:          o.OrderUID,
+		TrackNumber:       o.TrackNumber,
+		Entry:             o.Entry,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerID:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		ShardKey:          o.Shardkey,
+		SmID:              o.SmID,
+		DateCreated:       &parsedDateCreated,
+		OofShard:          o.OofShard,
+		Status:            models.StatusCreated,
+		Items:             items,
+	}
+
+	return order, delivery, payment, nil
 }
 
 func GetAllOrders(ctx context.Context, db *gorm.DB) ([]dto.OrderDTO, error) {
@@ -124,6 +165,7 @@ func GetAllOrders(ctx context.Context, db *gorm.DB) ([]dto.OrderDTO, error) {
 			SmID:              o.SmID,
 			DateCreated:       o.DateCreated.Format(time.RFC3339),
 			OofShard:          o.OofShard,
+			Status:            string(o.Status),
 		}
 
 		if o.Delivery.ID != 0 {
@@ -198,6 +240,7 @@ func GetOrderByUID(ctx context.Context, db *gorm.DB, orderUID string) (*dto.Orde
 		SmID:              order.SmID,
 		DateCreated:       order.DateCreated.Format(time.RFC3339),
 		OofShard:          order.OofShard,
+		Status:            string(order.Status),
 	}
 
 	if order.Delivery.ID != 0 {
@@ -245,3 +288,186 @@ func GetOrderByUID(ctx context.Context, db *gorm.DB, orderUID string) (*dto.Orde
 
 	return &result, nil
 }
+
+// UpdateOrderStatus применяет смену статуса, если текущий статус заказа равен change.From,
+// и записывает её в order_status_history
+func UpdateOrderStatus(ctx context.Context, db *gorm.DB, change repository.StatusChange) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Select("id").Where("order_uid = ?", change.OrderUID).First(&order).Error; err != nil {
+			return err
+		}
+
+		// Условие на статус защищает от одновременной смены статуса другим запросом.
+		// updated_at - момент записи, а не события: по нему кэш догружается после снимка.
+		res := tx.Model(&models.Order{}).
+			Where("id = ? AND status = ?", order.ID, change.From).
+			Updates(map[string]interface{}{
+				"status":     change.To,
+				"updated_at": time.Now(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return repository.ErrStatusConflict
+		}
+
+		history := models.OrderStatusChange{
+			OrderID:    order.ID,
+			FromStatus: change.From,
+			ToStatus:   change.To,
+			Reason:     change.Reason,
+			ChangedAt:  change.ChangedAt,
+		}
+		if change.EventID != "" {
+			history.EventID = &change.EventID
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			if change.EventID != "" && IsUniqueViolation(err) {
+				return repository.ErrEventProcessed
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func StatusEventProcessed(ctx context.Context, db *gorm.DB, eventID string) (bool, error) {
+	var processed bool
+	err := db.WithContext(ctx).Raw(
+		"SELECT EXISTS (SELECT 1 FROM order_status_history WHERE event_id = ?) "+
+			"OR EXISTS (SELECT 1 FROM skipped_status_events WHERE event_id = ?)",
+		eventID, eventID).
+		Scan(&processed).Error
+	return processed, err
+}
+
+func MarkStatusEventSkipped(ctx context.Context, db *gorm.DB, event repository.StatusEvent) error {
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.SkippedStatusEvent{
+			EventID:  event.EventID,
+			OrderUID: event.OrderUID,
+		}).Error
+}
+
+func SavePendingStatusEvent(ctx context.Context, db *gorm.DB, event repository.StatusEvent) error {
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.PendingStatusEvent{
+			EventID:    event.EventID,
+			OrderUID:   event.OrderUID,
+			Status:     event.Status,
+			Reason:     event.Reason,
+			OccurredAt: event.OccurredAt,
+		}).Error
+}
+
+func PendingStatusEvents(ctx context.Context, db *gorm.DB, orderUID string) ([]repository.StatusEvent, error) {
+	var pending []models.PendingStatusEvent
+	if err := db.WithContext(ctx).
+		Where("order_uid = ?", orderUID).
+		Order("occurred_at, received_at").
+		Find(&pending).Error; err != nil {
+		return nil, err
+	}
+
+	events := make([]repository.StatusEvent, 0, len(pending))
+	for _, p := range pending {
+		events = append(events, repository.StatusEvent{
+			EventID:    p.EventID,
+			OrderUID:   p.OrderUID,
+			Status:     p.Status,
+			Reason:     p.Reason,
+			OccurredAt: p.OccurredAt,
+		})
+	}
+	return events, nil
+}
+
+func DeletePendingStatusEvent(ctx context.Context, db *gorm.DB, eventID string) error {
+	return db.WithContext(ctx).
+		Where("event_id = ?", eventID).
+		Delete(&models.PendingStatusEvent{}).Error
+}
+
+func OrdersWithPendingStatusEvents(ctx context.Context, db *gorm.DB, limit int) ([]string, error) {
+	var orderUIDs []string
+	err := db.WithContext(ctx).
+		Model(&models.PendingStatusEvent{}).
+		Joins("JOIN orders ON orders.order_uid = pending_status_events.order_uid").
+		Distinct().
+		Limit(limit).
+		Pluck("pending_status_events.order_uid", &orderUIDs).Error
+	return orderUIDs, err
+}
+
+func PurgePendingStatusEvents(ctx context.Context, db *gorm.DB, receivedBefore time.Time) (int64, error) {
+	res := db.WithContext(ctx).
+		Where("received_at < ?", receivedBefore).
+		Delete(&models.PendingStatusEvent{})
+	return res.RowsAffected, res.Error
+}
+
+func ListOrders(ctx context.Context, db *gorm.DB, filter repository.OrderFilter) (*repository.OrderPage, error) {
+	limit := filter.PageSize()
+
+	query := db.WithContext(ctx).
+		Preload("Delivery").
+		Preload("Payment").
+		Preload("Items")
+
+	if filter.Cursor != "" {
+		lastID, err := repository.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("id < ?", lastID)
+	}
+	if filter.CustomerID != "" {
+		query = query.Where("customer_id = ?", filter.CustomerID)
+	}
+	if filter.DeliveryService != "" {
+		query = query.Where("delivery_service = ?", filter.DeliveryService)
+	}
+	if filter.Entry != "" {
+		query = query.Where("entry = ?", filter.Entry)
+	}
+	if filter.Locale != "" {
+		query = query.Where("locale = ?", filter.Locale)
+	}
+	if filter.DateFrom != nil {
+		query = query.Where("date_created >= ?", *filter.DateFrom)
+	}
+	if filter.DateTo != nil {
+		query = query.Where("date_created < ?", *filter.DateTo)
+	}
+	if filter.UpdatedAfter != nil {
+		query = query.Where("updated_at > ?", *filter.UpdatedAfter)
+	}
+
+	// Берём на одну запись больше, чтобы понять, есть ли следующая страница
+	var orders []models.Order
+	if err := query.
+		Order("id DESC").
+		Limit(limit + 1).
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	page := &repository.OrderPage{
+		Orders: make([]dto.OrderDTO, 0, limit),
+	}
+
+	if len(orders) > limit {
+		orders = orders[:limit]
+		page.NextCursor = repository.EncodeCursor(orders[limit-1].ID)
+	}
+
+	for i := range orders {
+		page.Orders = append(page.Orders, *convertToDTO(&orders[i]))
+	}
+
+	return page, nil
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"L0/internal/kafka/dto"
+	"L0/internal/models"
+	"L0/internal/repository"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// outboxLockKey - ключ advisory-блокировки relay. События публикует одна реплика за раз,
+// иначе события одного заказа могли бы уйти в Kafka не по порядку.
+const outboxLockKey int64 = 0x4c304f7574626f78
+
+// WriteOrdersPersisted записывает в outbox события о сохранённых заказах. Вызывается в транзакции создания заказов.
+func WriteOrdersPersisted(tx *gorm.DB, orders ...*models.Order) error {
+	events := make([]models.OutboxEvent, 0, len(orders))
+	for _, order := range orders {
+		payload, err := json.Marshal(dto.OrderPersistedEvent{
+			EventID:     dto.EventOrderPersisted + ":" + order.OrderUID,
+			Type:        dto.EventOrderPersisted,
+			OrderUID:    order.OrderUID,
+			PersistedAt: order.CreatedAt.UTC(),
+			Order:       convertToDTO(order),
+		})
+		if err != nil {
+			return err
+		}
+		events = append(events, models.OutboxEvent{
+			AggregateID: order.OrderUID,
+			EventType:   dto.EventOrderPersisted,
+			Payload:     payload,
+		})
+	}
+
+	if err := tx.Create(&events).Error; err != nil {
+		return fmt.Errorf("failed to write outbox event: %w", err)
+	}
+	return nil
+}
+
+// PublishOutbox публикует события в транзакции, удерживающей advisory-блокировку: так порядок
+// событий сохраняется между репликами. Вызывающий ограничивает publish по времени,
+// иначе недоступный брокер держит транзакцию и соединение открытыми.
+func PublishOutbox(ctx context.Context, db *gorm.DB, limit int, publish func(ctx context.Context, events []repository.OutboxEvent) error) (int, error) {
+	published := 0
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", outboxLockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		var rows []models.OutboxEvent
+		if err := tx.Order("id").Limit(limit).Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		events := make([]repository.OutboxEvent, 0, len(rows))
+		ids := make([]uint64, 0, len(rows))
+		for _, row := range rows {
+			events = append(events, repository.OutboxEvent{
+				ID:          row.ID,
+				AggregateID: row.AggregateID,
+				EventType:   row.EventType,
+				Payload:     row.Payload,
+				CreatedAt:   row.CreatedAt,
+			})
+			ids = append(ids, row.ID)
+		}
+
+		// Если коммит не пройдёт после публикации, события уйдут повторно: доставка at-least-once
+		if err := publish(ctx, events); err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&models.OutboxEvent{}).Error; err != nil {
+			return err
+		}
+		published = len(rows)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return published, nil
+}
+
+func OutboxLag(ctx context.Context, db *gorm.DB) (int64, time.Time, error) {
+	var lag struct {
+		Pending int64
+		Oldest  *time.Time
+	}
+	if err := db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Select("COUNT(*) AS pending, MIN(created_at) AS oldest").
+		Scan(&lag).Error; err != nil {
+		return 0, time.Time{}, err
+	}
+	if lag.Oldest == nil {
+		return lag.Pending, time.Time{}, nil
+	}
+	return lag.Pending, *lag.Oldest, nil
+}
+
+var _ repository.Outbox = (*Storage)(nil)
@@ -2,7 +2,9 @@ package postgres
 
 import (
 	"L0/internal/kafka/dto"
+	"L0/internal/metrics"
 	"L0/internal/models"
+	"L0/internal/repository"
 	"context"
 	"errors"
 	"fmt"
@@ -19,22 +21,161 @@ import (
 
 type Storage struct {
 	DB *gorm.DB
+	// Notifier, если задан, сообщает другим репликам о записанных заказах
+	Notifier *Notifier
+	// WriteOutbox включает запись события order.persisted в outbox в транзакции создания заказа
+	WriteOutbox bool
 }
 
 func (s *Storage) CreateOrder(ctx context.Context, o *dto.OrderDTO) (*dto.OrderDTO, error) {
-	order, err := CreateOrder(ctx, s.DB, o)
+	defer observeQuery("create_order", time.Now())
+
+	var order *models.Order
+	err := s.withNotify(ctx, OpCreated, func(tx *gorm.DB) error {
+		var err error
+		order, err = CreateOrder(ctx, tx, o)
+		if err != nil || !s.WriteOutbox {
+			return err
+		}
+		return WriteOrdersPersisted(tx, order)
+	}, o.OrderUID)
 	if err != nil {
-		return nil, err
+		return nil, classifyCreateError(err)
 	}
 	return convertToDTO(order), nil
 }
 
+func (s *Storage) CreateOrders(ctx context.Context, orders []*dto.OrderDTO) ([]*dto.OrderDTO, error) {
+	defer observeQuery("create_orders", time.Now())
+
+	uids := make([]string, 0, len(orders))
+	for _, o := range orders {
+		uids = append(uids, o.OrderUID)
+	}
+
+	var created []models.Order
+	err := s.withNotify(ctx, OpCreated, func(tx *gorm.DB) error {
+		var err error
+		created, err = CreateOrders(ctx, tx, orders)
+		if err != nil || !s.WriteOutbox {
+			return err
+		}
+		persisted := make([]*models.Order, 0, len(created))
+		for i := range created {
+			persisted = append(persisted, &created[i])
+		}
+		return WriteOrdersPersisted(tx, persisted...)
+	}, uids...)
+	if err != nil {
+		return nil, classifyCreateError(err)
+	}
+
+	result := make([]*dto.OrderDTO, 0, len(created))
+	for i := range created {
+		result = append(result, convertToDTO(&created[i]))
+	}
+	return result, nil
+}
+
 func (s *Storage) GetAllOrders(ctx context.Context) ([]dto.OrderDTO, error) {
+	defer observeQuery("get_all_orders", time.Now())
 	return GetAllOrders(ctx, s.DB)
 }
 
 func (s *Storage) GetOrderByUID(ctx context.Context, orderUID string) (*dto.OrderDTO, error) {
-	return GetOrderByUID(ctx, s.DB, orderUID)
+	defer observeQuery("get_order_by_uid", time.Now())
+	order, err := GetOrderByUID(ctx, s.DB, orderUID)
+	return order, markPermanent(err)
+}
+
+func (s *Storage) ListOrders(ctx context.Context, filter repository.OrderFilter) (*repository.OrderPage, error) {
+	defer observeQuery("list_orders", time.Now())
+	return ListOrders(ctx, s.DB, filter)
+}
+
+func (s *Storage) UpdateOrderStatus(ctx context.Context, change repository.StatusChange) error {
+	defer observeQuery("update_order_status", time.Now())
+	err := s.withNotify(ctx, OpStatusChanged, func(tx *gorm.DB) error {
+		return UpdateOrderStatus(ctx, tx, change)
+	}, change.OrderUID)
+	return markPermanent(err)
+}
+
+func (s *Storage) StatusEventProcessed(ctx context.Context, eventID string) (bool, error) {
+	defer observeQuery("status_event_processed", time.Now())
+	processed, err := StatusEventProcessed(ctx, s.DB, eventID)
+	return processed, markPermanent(err)
+}
+
+func (s *Storage) MarkStatusEventSkipped(ctx context.Context, event repository.StatusEvent) error {
+	defer observeQuery("mark_status_event_skipped", time.Now())
+	return markPermanent(MarkStatusEventSkipped(ctx, s.DB, event))
+}
+
+func (s *Storage) SavePendingStatusEvent(ctx context.Context, event repository.StatusEvent) error {
+	defer observeQuery("save_pending_status_event", time.Now())
+	return markPermanent(SavePendingStatusEvent(ctx, s.DB, event))
+}
+
+func (s *Storage) PendingStatusEvents(ctx context.Context, orderUID string) ([]repository.StatusEvent, error) {
+	defer observeQuery("pending_status_events", time.Now())
+	events, err := PendingStatusEvents(ctx, s.DB, orderUID)
+	return events, markPermanent(err)
+}
+
+func (s *Storage) DeletePendingStatusEvent(ctx context.Context, eventID string) error {
+	defer observeQuery("delete_pending_status_event", time.Now())
+	return markPermanent(DeletePendingStatusEvent(ctx, s.DB, eventID))
+}
+
+func (s *Storage) OrdersWithPendingStatusEvents(ctx context.Context, limit int) ([]string, error) {
+	defer observeQuery("orders_with_pending_status_events", time.Now())
+	orderUIDs, err := OrdersWithPendingStatusEvents(ctx, s.DB, limit)
+	return orderUIDs, markPermanent(err)
+}
+
+func (s *Storage) PurgePendingStatusEvents(ctx context.Context, receivedBefore time.Time) (int64, error) {
+	defer observeQuery("purge_pending_status_events", time.Now())
+	purged, err := PurgePendingStatusEvents(ctx, s.DB, receivedBefore)
+	return purged, markPermanent(err)
+}
+
+func (s *Storage) PublishOutbox(ctx context.Context, limit int, publish func(ctx context.Context, events []repository.OutboxEvent) error) (int, error) {
+	defer observeQuery("publish_outbox", time.Now())
+	return PublishOutbox(ctx, s.DB, limit, publish)
+}
+
+func (s *Storage) OutboxLag(ctx context.Context) (int64, time.Time, error) {
+	defer observeQuery("outbox_lag", time.Now())
+	return OutboxLag(ctx, s.DB)
+}
+
+// withNotify выполняет write и NOTIFY о каждом из orderUIDs в одной транзакции. Без Notifier транзакция
+// всё равно нужна: write может писать в outbox вместе с заказом.
+func (s *Storage) withNotify(ctx context.Context, op string, write func(tx *gorm.DB) error, orderUIDs ...string) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := write(tx); err != nil {
+			return err
+		}
+		if s.Notifier == nil {
+			return nil
+		}
+		for _, orderUID := range orderUIDs {
+			if err := s.Notifier.NotifyOrderChanged(tx, orderUID, op); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Ping checks that the database is reachable
+func (s *Storage) Ping(ctx context.Context) error {
+	sqlDB, err := s.DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	return sqlDB.PingContext(ctx)
 }
 
 // Close closes the database connection
@@ -46,13 +187,16 @@ func (s *Storage) Close() error {
 	return sqlDB.Close()
 }
 
-func New(host string, port int, user, password, dbname string) (*Storage, error) {
-	dsn := fmt.Sprintf(
+// DSN собирает строку подключения в формате key=value
+func DSN(host string, port int, user, password, dbname string) string {
+	return fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		host, port, user, password, dbname,
 	)
+}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+func New(host string, port int, user, password, dbname string) (*Storage, error) {
+	db, err := gorm.Open(postgres.Open(DSN(host, port, user, password, dbname)), &gorm.Config{})
 	if err != nil {
 		return nil, fmt.Errorf("failed opening postgres connection: %w", err)
 	}
@@ -99,6 +243,11 @@ func RollbackLast(host string, port int, user, password, dbname string) error {
 	return m.Steps(-1)
 }
 
+// observeQuery записывает длительность операции Storage в метрики
+func observeQuery(operation string, start time.Time) {
+	metrics.DBQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
 func convertToDTO(order *models.Order) *dto.OrderDTO {
 	if order == nil {
 		return nil
@@ -116,6 +265,7 @@ func convertToDTO(order *models.Order) *dto.OrderDTO {
 		SmID:              order.SmID,
 		DateCreated:       order.DateCreated.Format(time.RFC3339),
 		OofShard:          order.OofShard,
+		Status:            string(order.Status),
 	}
 
 	if order.Delivery.ID != 0 {
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"L0/internal/models"
+	"time"
+)
+
+// StatusChange - смена статуса заказа From -> To. Применяется, только если
+// текущий статус заказа всё ещё From, иначе возвращается ErrStatusConflict.
+type StatusChange struct {
+	OrderUID  string
+	From      models.OrderStatus
+	To        models.OrderStatus
+	Reason    string
+	ChangedAt time.Time
+	// EventID - идентификатор внешнего события; если оно уже применено, возвращается ErrEventProcessed
+	EventID string
+}
+
+// StatusEvent - внешнее событие смены статуса заказа
+type StatusEvent struct {
+	EventID    string
+	OrderUID   string
+	Status     models.OrderStatus
+	Reason     string
+	OccurredAt time.Time
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrRewarmInProgress возвращается, если повторный прогрев уже запущен
+var ErrRewarmInProgress = errors.New("cache rewarm is already in progress")
+
+// CacheStats - состояние кэша для администратора
+type CacheStats struct {
+	Size          int          `json:"size"`
+	SizeTruncated bool         `json:"size_truncated,omitempty"` // перечисление ключей оборвалось, Size неполон
+	Hits          uint64       `json:"hits"`
+	Misses        uint64       `json:"misses"`
+	Evictions     uint64       `json:"evictions"`
+	OldestEntry   *OldestEntry `json:"oldest_entry,omitempty"`
+	Rewarm        RewarmStatus `json:"rewarm"`
+}
+
+// OldestEntry - самая старая запись кэша
+type OldestEntry struct {
+	OrderUID string    `json:"order_uid"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// RewarmStatus - состояние последнего повторного прогрева
+type RewarmStatus struct {
+	Running    bool       `json:"running"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Loaded     int        `json:"loaded"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type cacheAdmin struct {
+	// ctx - время жизни сервиса: прогрев идёт в фоне и не привязан к HTTP-запросу
+	ctx    context.Context
+	cache  cache.Cache[string, *dto.OrderDTO]
+	warmer *CacheWarmer
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	rewarm RewarmStatus
+}
+
+// NewCacheAdmin создаёт сервис администрирования кэша. Повторный прогрев
+// останавливается при отмене ctx.
+func NewCacheAdmin(ctx context.Context, cache cache.Cache[string, *dto.OrderDTO], warmer *CacheWarmer, logger *slog.Logger) CacheAdmin {
+	return &cacheAdmin{
+		ctx:    ctx,
+		cache:  cache,
+		warmer: warmer,
+		logger: logger,
+	}
+}
+
+func (a *cacheAdmin) Stats() CacheStats {
+	var stats CacheStats
+	if scanner, ok := a.cache.(cache.KeyScanner[string]); ok {
+		keys, err := scanner.ScanKeys(a.ctx)
+		stats.Size = len(keys)
+		if err != nil {
+			stats.SizeTruncated = true
+			a.logger.Warn("Cache size is incomplete", slog.String("error", err.Error()))
+		}
+	} else {
+		stats.Size = a.cache.Len()
+	}
+
+	if provider, ok := a.cache.(cache.StatsProvider); ok {
+		s := provider.Stats()
+		stats.Hits, stats.Misses, stats.Evictions = s.Hits, s.Misses, s.Evictions
+	}
+
+	if provider, ok := a.cache.(cache.OldestEntryProvider[string]); ok {
+		if key, storedAt, found := provider.OldestEntry(); found {
+			stats.OldestEntry = &OldestEntry{OrderUID: key, StoredAt: storedAt}
+		}
+	}
+
+	stats.Rewarm = a.RewarmStatus()
+	return stats
+}
+
+func (a *cacheAdmin) RewarmStatus() RewarmStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.rewarm
+}
+
+func (a *cacheAdmin) Evict(orderUID string) {
+	a.cache.Delete(orderUID)
+	a.logger.Info("Order evicted from cache by admin", slog.String("order_uid", orderUID))
+}
+
+func (a *cacheAdmin) Flush() {
+	a.cache.Flush()
+	a.logger.Info("Cache flushed by admin")
+}
+
+func (a *cacheAdmin) Rewarm() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.rewarm.Running {
+		return ErrRewarmInProgress
+	}
+
+	startedAt := time.Now()
+	a.rewarm = RewarmStatus{Running: true, StartedAt: &startedAt}
+	go a.runRewarm()
+
+	return nil
+}
+
+func (a *cacheAdmin) runRewarm() {
+	a.logger.Info("Cache rewarm requested by admin")
+	loaded, err := a.warmer.Run(a.ctx)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	finishedAt := time.Now()
+	a.rewarm.Running = false
+	a.rewarm.FinishedAt = &finishedAt
+	a.rewarm.Loaded = loaded
+	if err != nil {
+		a.rewarm.Error = err.Error()
+		a.logger.Error("Cache rewarm failed", slog.String("error", err.Error()))
+	}
+}
@@ -2,10 +2,39 @@ package service
 
 import (
 	"L0/internal/kafka/dto"
+	"L0/internal/models"
+	"L0/internal/repository"
 	"context"
 )
 
 type OrderService interface {
 	GetOrder(ctx context.Context, orderUID string) (*dto.OrderDTO, error)
 	CreateOrder(ctx context.Context, order *dto.OrderDTO) (*dto.OrderDTO, error)
+	// CreateOrders сохраняет заказы одной транзакцией: либо все, либо ни одного
+	CreateOrders(ctx context.Context, orders []*dto.OrderDTO) ([]*dto.OrderDTO, error)
+	ListOrders(ctx context.Context, filter repository.OrderFilter) (*repository.OrderPage, error)
+	// UpdateOrderStatus переводит заказ в статус status, если переход разрешён
+	UpdateOrderStatus(ctx context.Context, orderUID string, status models.OrderStatus, reason string) (*dto.OrderDTO, error)
+	// ApplyStatusEvent применяет событие смены статуса из внешней системы
+	ApplyStatusEvent(ctx context.Context, event *dto.StatusEventDTO) error
+	// ApplyPendingStatusEvents применяет отложенные события уже сохранённого заказа
+	ApplyPendingStatusEvents(ctx context.Context, orderUID string) error
+	// ForgetMissing удаляет orderUIDs из кэша отсутствующих заказов, а без аргументов очищает его
+	ForgetMissing(orderUIDs ...string)
+}
+
+// OutboxPublisher публикует события outbox во внешний брокер
+type OutboxPublisher interface {
+	PublishEvents(ctx context.Context, events []repository.OutboxEvent) error
+}
+
+// CacheAdmin - операции администратора над кэшем заказов
+type CacheAdmin interface {
+	Stats() CacheStats
+	Evict(orderUID string)
+	Flush()
+	// Rewarm запускает прогрев кэша из БД в фоне и сразу возвращается
+	Rewarm() error
+	// RewarmStatus возвращает состояние последнего прогрева, не обращаясь к кэшу
+	RewarmStatus() RewarmStatus
 }
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"L0/internal/repository/postgres"
+	"errors"
+	"log/slog"
+)
+
+// CacheInvalidator применяет к кэшу реплики изменения заказов, сделанные другими репликами
+type CacheInvalidator struct {
+	cache  cache.Cache[string, *dto.OrderDTO]
+	admin  CacheAdmin
+	orders OrderService
+	logger *slog.Logger
+}
+
+func NewCacheInvalidator(cache cache.Cache[string, *dto.OrderDTO], admin CacheAdmin, orders OrderService, logger *slog.Logger) *CacheInvalidator {
+	return &CacheInvalidator{
+		cache:  cache,
+		admin:  admin,
+		orders: orders,
+		logger: logger,
+	}
+}
+
+// OrderChanged удаляет заказ из кэша: свежая версия загрузится из БД при следующем запросе.
+// У общего кэша удаляется только локальная копия: общую запись уже обновила пишущая реплика.
+// Заказ, созданный другой репликой, перестаёт считаться отсутствующим.
+func (i *CacheInvalidator) OrderChanged(change postgres.OrderChange) {
+	if local, ok := i.cache.(cache.LocalFlusher); ok {
+		local.DeleteLocal(change.OrderUID)
+	} else {
+		i.cache.Delete(change.OrderUID)
+	}
+	i.orders.ForgetMissing(change.OrderUID)
+	i.logger.Debug("Order invalidated by another replica",
+		slog.String("order_uid", change.OrderUID),
+		slog.String("op", change.Op),
+		slog.String("origin", change.Origin))
+}
+
+// Resync сбрасывает локальные кэши, так как уведомления за время разрыва потеряны. У общего кэша
+// очищается только локальный уровень: общее хранилище обновляют сами пишущие реплики, а его сброс
+// остудил бы кэш всем репликам сразу. Локальный кэш целиком сбрасывается и прогревается заново.
+func (i *CacheInvalidator) Resync() {
+	i.logger.Warn("Order change notifications may have been lost, resyncing cache")
+	i.orders.ForgetMissing()
+
+	if local, ok := i.cache.(cache.LocalFlusher); ok {
+		local.FlushLocal()
+		return
+	}
+
+	i.admin.Flush()
+	if err := i.admin.Rewarm(); err != nil && !errors.Is(err, ErrRewarmInProgress) {
+		i.logger.Error("Failed to start cache rewarm", slog.String("error", err.Error()))
+	}
+}
+
+var _ postgres.ChangeHandler = (*CacheInvalidator)(nil)
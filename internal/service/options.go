@@ -0,0 +1,43 @@
+package service
+
+import (
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"time"
+)
+
+// Option настраивает orderService
+type Option func(*orderService)
+
+// WithNegativeCache включает кэширование отсутствующих заказов: повторный запрос
+// несуществующего order_uid в течение ttl не доходит до БД. Отрицательные записи
+// хранятся в отдельном LRU на maxEntries записей и удаляются при создании заказа.
+func WithNegativeCache(ttl time.Duration, maxEntries int) Option {
+	return func(s *orderService) {
+		if ttl <= 0 {
+			return
+		}
+		s.negative = cache.NewLRU(cache.LRUOptions[string, struct{}]{
+			MaxEntries: maxEntries,
+			DefaultTTL: ttl,
+		})
+	}
+}
+
+// WithRefreshAhead включает упреждающее обновление кэша: запись, до истечения которой
+// осталось меньше window, отдаётся сразу, а в фоне перечитывается из БД. Работает только
+// с кэшами, которые сообщают время истечения записей (cache.ExpirationProvider).
+func WithRefreshAhead(window time.Duration) Option {
+	return func(s *orderService) {
+		if window <= 0 {
+			return
+		}
+		expiring, ok := s.cache.(cache.ExpirationProvider[string, *dto.OrderDTO])
+		if !ok {
+			s.logger.Warn("Cache does not report expiration, refresh-ahead is disabled")
+			return
+		}
+		s.refreshWindow = window
+		s.expiring = expiring
+	}
+}
@@ -3,53 +3,148 @@ package service
 import (
 	"L0/internal/cache"
 	"L0/internal/kafka/dto"
+	"L0/internal/models"
 	"L0/internal/repository"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
+	"time"
 
-	gocache "github.com/patrickmn/go-cache"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
 )
 
+// orderService хранит в кэше собственные копии заказов и отдаёт вызывающим
+// только копии, поэтому изменение результата не портит кэш для остальных
 type orderService struct {
 	repo   repository.Repository
-	cache  cache.Cache
+	cache  cache.Cache[string, *dto.OrderDTO]
 	logger *slog.Logger
+
+	// loads объединяет одновременные промахи кэша по одному order_uid в один запрос к БД
+	loads singleflight.Group
+	// negative хранит order_uid, которых нет в БД; nil - отрицательное кэширование выключено
+	negative cache.Cache[string, struct{}]
+	// created увеличивается при каждом созданном заказе, чтобы запрос к БД,
+	// начатый до создания, не закэшировал устаревший "не найден"
+	created atomic.Uint64
+	// refreshWindow - за сколько до истечения запись перечитывается из БД в фоне; 0 - выключено
+	refreshWindow time.Duration
+	// expiring - кэш, сообщающий время истечения записей; nil, если кэш этого не умеет
+	expiring cache.ExpirationProvider[string, *dto.OrderDTO]
 }
 
-func NewOrderService(repo repository.Repository, cache cache.Cache, logger *slog.Logger) OrderService {
-	return &orderService{
+// refreshTimeout ограничивает фоновое обновление записи кэша
+const refreshTimeout = 5 * time.Second
+
+func NewOrderService(repo repository.Repository, cache cache.Cache[string, *dto.OrderDTO], logger *slog.Logger, opts ...Option) OrderService {
+	s := &orderService{
 		repo:   repo,
 		cache:  cache,
 		logger: logger,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *orderService) GetOrder(ctx context.Context, orderUID string) (*dto.OrderDTO, error) {
-	if cachedOrder, found := s.cache.Get(orderUID); found {
-		if order, ok := cachedOrder.(*dto.OrderDTO); ok {
-			s.logger.Debug("Order found in cache", slog.String("order_uid", orderUID))
-			return order, nil
+	if order, found := s.cachedOrder(orderUID); found {
+		s.logger.Debug("Order found in cache", slog.String("order_uid", orderUID))
+		return order.Clone(), nil
+	}
+
+	if s.negative != nil {
+		if _, found := s.negative.Get(orderUID); found {
+			s.logger.Debug("Order is known to be missing", slog.String("order_uid", orderUID))
+			return nil, fmt.Errorf("failed to get order: %w", gorm.ErrRecordNotFound)
 		}
+	}
+
+	// Запрос выполняется без отмены: его результат ждут и другие вызовы,
+	// а каждый вызов перестаёт ждать при отмене своего контекста
+	loadCtx := context.WithoutCancel(ctx)
+	result := s.loads.DoChan(orderUID, func() (interface{}, error) {
+		s.logger.Info("Order not found in cache, fetching from database", slog.String("order_uid", orderUID))
+		return s.loadOrder(loadCtx, orderUID)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to get order: %w", ctx.Err())
+	case res := <-result:
+		if res.Err != nil {
+			return nil, fmt.Errorf("failed to get order: %w", res.Err)
+		}
+		if res.Shared {
+			s.logger.Debug("Order lookup coalesced", slog.String("order_uid", orderUID))
+		}
+		// Результат общий для всех ожидавших вызовов и лежит в кэше
+		return res.Val.(*dto.OrderDTO).Clone(), nil
+	}
+}
+
+// cachedOrder читает заказ из кэша. Если до истечения записи осталось меньше refreshWindow,
+// запускает её фоновое обновление и отдаёт текущее значение (stale-while-revalidate).
+func (s *orderService) cachedOrder(orderUID string) (*dto.OrderDTO, bool) {
+	if s.refreshWindow <= 0 || s.expiring == nil {
+		return s.cache.Get(orderUID)
+	}
 
-		s.cache.Delete(orderUID)
-		s.logger.Warn("Invalid type in cache, removed", slog.String("order_uid", orderUID))
+	order, expiresAt, found := s.expiring.GetWithExpiration(orderUID)
+	if found && !expiresAt.IsZero() && time.Until(expiresAt) < s.refreshWindow {
+		s.refresh(orderUID)
 	}
+	return order, found
+}
 
-	s.logger.Info("Order not found in cache, fetching from database", slog.String("order_uid", orderUID))
+// refresh перечитывает заказ из БД в фоне. Обновление идёт через ту же группу singleflight,
+// что и промахи кэша, поэтому по одному order_uid одновременно выполняется не больше одного запроса.
+func (s *orderService) refresh(orderUID string) {
+	s.loads.DoChan(orderUID, func() (interface{}, error) {
+		s.logger.Debug("Refreshing cached order ahead of expiration", slog.String("order_uid", orderUID))
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+		return s.loadOrder(ctx, orderUID)
+	})
+}
 
+// loadOrder читает заказ из БД и кладёт в кэш; используется и при промахе, и при фоновом обновлении
+func (s *orderService) loadOrder(ctx context.Context, orderUID string) (*dto.OrderDTO, error) {
+	created := s.created.Load()
 	order, err := s.repo.GetOrderByUID(ctx, orderUID)
 	if err != nil {
-		s.logger.Error("Failed to get order from database", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to get order: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Заказ мог пропасть из БД, пока устаревшая запись обновлялась в фоне
+			s.cache.Delete(orderUID)
+			s.rememberMissing(orderUID, created)
+		} else {
+			s.logger.Error("Failed to get order from database", slog.String("error", err.Error()))
+		}
+		return nil, err
 	}
 
-	s.cache.Set(orderUID, order, gocache.DefaultExpiration)
+	s.cache.Set(orderUID, order, cache.DefaultExpiration)
 	s.logger.Debug("Order cached", slog.String("order_uid", orderUID))
 
 	return order, nil
 }
 
+// rememberMissing кэширует отсутствие заказа, если с начала запроса к БД не было создано ни одного заказа.
+// Проверка идёт после записи: CreateOrder, начавшийся позже, сам удалит запись.
+func (s *orderService) rememberMissing(orderUID string, createdBefore uint64) {
+	if s.negative == nil {
+		return
+	}
+	s.negative.Set(orderUID, struct{}{}, cache.DefaultExpiration)
+	if s.created.Load() != createdBefore {
+		s.negative.Delete(orderUID)
+	}
+}
+
 func (s *orderService) CreateOrder(ctx context.Context, order *dto.OrderDTO) (*dto.OrderDTO, error) {
 	createdOrder, err := s.repo.CreateOrder(ctx, order)
 	if err != nil {
@@ -57,8 +152,237 @@ func (s *orderService) CreateOrder(ctx context.Context, order *dto.OrderDTO) (*d
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
 
-	s.cache.Set(createdOrder.OrderUID, createdOrder, gocache.DefaultExpiration)
+	s.orderCreated(ctx, createdOrder)
+	return createdOrder, nil
+}
+
+// CreateOrders сохраняет заказы одной транзакцией и кладёт их в кэш
+func (s *orderService) CreateOrders(ctx context.Context, orders []*dto.OrderDTO) ([]*dto.OrderDTO, error) {
+	createdOrders, err := s.repo.CreateOrders(ctx, orders)
+	if err != nil {
+		s.logger.Error("Failed to create orders",
+			slog.Int("count", len(orders)),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to create orders: %w", err)
+	}
+
+	for _, createdOrder := range createdOrders {
+		s.orderCreated(ctx, createdOrder)
+	}
+	return createdOrders, nil
+}
+
+func (s *orderService) ForgetMissing(orderUIDs ...string) {
+	if s.negative == nil {
+		return
+	}
+	if len(orderUIDs) == 0 {
+		s.negative.Flush()
+		return
+	}
+	for _, orderUID := range orderUIDs {
+		s.negative.Delete(orderUID)
+	}
+}
+
+// orderCreated кэширует сохранённый заказ и применяет отложенные для него события
+func (s *orderService) orderCreated(ctx context.Context, createdOrder *dto.OrderDTO) {
+	s.created.Add(1)
+	if s.negative != nil {
+		s.negative.Delete(createdOrder.OrderUID)
+	}
+
+	s.cache.Set(createdOrder.OrderUID, createdOrder.Clone(), cache.DefaultExpiration)
 	s.logger.Info("Order created and cached", slog.String("order_uid", createdOrder.OrderUID))
 
-	return createdOrder, nil
+	// Ошибка не отменяет создание: отложенные события применит PendingStatusSweeper
+	if err := s.applyPendingStatusEvents(ctx, createdOrder.OrderUID); err != nil {
+		s.logger.Error("Failed to apply pending status events",
+			slog.String("order_uid", createdOrder.OrderUID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// UpdateOrderStatus проверяет переход по текущему статусу из БД, а не из кэша,
+// и кладёт обновлённый заказ в кэш
+func (s *orderService) UpdateOrderStatus(ctx context.Context, orderUID string, status models.OrderStatus, reason string) (*dto.OrderDTO, error) {
+	order, err := s.repo.GetOrderByUID(ctx, orderUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	from := models.OrderStatus(order.Status)
+	if err := models.ValidateTransition(from, status); err != nil {
+		return nil, fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	change := repository.StatusChange{
+		OrderUID:  orderUID,
+		From:      from,
+		To:        status,
+		Reason:    reason,
+		ChangedAt: time.Now().UTC(),
+	}
+	if err := s.repo.UpdateOrderStatus(ctx, change); err != nil {
+		if !errors.Is(err, repository.ErrStatusConflict) && !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Error("Failed to update order status", slog.String("error", err.Error()))
+		}
+		return nil, fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	return s.cacheStatus(order, from, status).Clone(), nil
+}
+
+// ApplyStatusEvent применяет событие смены статуса. Уже применённое событие и событие,
+// переводящее заказ в его текущий статус, пропускаются. Событие для заказа, которого
+// ещё нет в БД, откладывается до его сохранения.
+func (s *orderService) ApplyStatusEvent(ctx context.Context, event *dto.StatusEventDTO) error {
+	status, err := models.ParseOrderStatus(event.Status)
+	if err != nil {
+		return fmt.Errorf("failed to apply status event: %w", err)
+	}
+	occurredAt, err := time.Parse(time.RFC3339, event.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to apply status event: %w", err)
+	}
+
+	processed, err := s.repo.StatusEventProcessed(ctx, event.EventID)
+	if err != nil {
+		return fmt.Errorf("failed to apply status event: %w", err)
+	}
+	if processed {
+		s.logger.Info("Status event already processed, skipping", slog.String("event_id", event.EventID))
+		return nil
+	}
+
+	statusEvent := repository.StatusEvent{
+		EventID:    event.EventID,
+		OrderUID:   event.OrderUID,
+		Status:     status,
+		Reason:     event.Reason,
+		OccurredAt: occurredAt,
+	}
+	err = s.applyStatusEvent(ctx, statusEvent)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.deferStatusEvent(ctx, statusEvent)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply status event: %w", err)
+	}
+	return nil
+}
+
+func (s *orderService) applyStatusEvent(ctx context.Context, event repository.StatusEvent) error {
+	order, err := s.repo.GetOrderByUID(ctx, event.OrderUID)
+	if err != nil {
+		return err
+	}
+
+	from := models.OrderStatus(order.Status)
+	if from == event.Status {
+		s.logger.Info("Order already has event status, skipping",
+			slog.String("event_id", event.EventID),
+			slog.String("order_uid", event.OrderUID),
+			slog.String("status", string(from)))
+		// Иначе повтор события после следующей смены статуса выглядел бы недопустимым переходом
+		return s.repo.MarkStatusEventSkipped(ctx, event)
+	}
+	if err := models.ValidateTransition(from, event.Status); err != nil {
+		return err
+	}
+
+	err = s.repo.UpdateOrderStatus(ctx, repository.StatusChange{
+		OrderUID:  event.OrderUID,
+		From:      from,
+		To:        event.Status,
+		Reason:    event.Reason,
+		ChangedAt: event.OccurredAt,
+		EventID:   event.EventID,
+	})
+	if errors.Is(err, repository.ErrEventProcessed) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.cacheStatus(order, from, event.Status)
+	return nil
+}
+
+// deferStatusEvent откладывает событие до сохранения заказа. Заказ мог сохраниться
+// между поиском и записью события, поэтому после записи отложенные события проверяются ещё раз.
+func (s *orderService) deferStatusEvent(ctx context.Context, event repository.StatusEvent) error {
+	if err := s.repo.SavePendingStatusEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to defer status event: %w", err)
+	}
+	s.logger.Info("Order not found, status event deferred",
+		slog.String("event_id", event.EventID),
+		slog.String("order_uid", event.OrderUID))
+
+	if err := s.applyPendingStatusEvents(ctx, event.OrderUID); err != nil {
+		return fmt.Errorf("failed to apply pending status events: %w", err)
+	}
+	return nil
+}
+
+func (s *orderService) ApplyPendingStatusEvents(ctx context.Context, orderUID string) error {
+	if err := s.applyPendingStatusEvents(ctx, orderUID); err != nil {
+		return fmt.Errorf("failed to apply pending status events: %w", err)
+	}
+	return nil
+}
+
+// applyPendingStatusEvents применяет отложенные события заказа по порядку и удаляет их.
+// Событие с недопустимым переходом отбрасывается, чтобы не блокировать следующие.
+func (s *orderService) applyPendingStatusEvents(ctx context.Context, orderUID string) error {
+	events, err := s.repo.PendingStatusEvents(ctx, orderUID)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		err := s.applyStatusEvent(ctx, event)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// Заказа всё ещё нет
+			return nil
+		case errors.Is(err, models.ErrIllegalTransition):
+			s.logger.Warn("Dropping pending status event",
+				slog.String("event_id", event.EventID),
+				slog.String("order_uid", orderUID),
+				slog.String("error", err.Error()))
+		case err != nil:
+			return err
+		}
+
+		if err := s.repo.DeletePendingStatusEvent(ctx, event.EventID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cacheStatus кладёт в кэш копию заказа с новым статусом и возвращает её
+func (s *orderService) cacheStatus(order *dto.OrderDTO, from, to models.OrderStatus) *dto.OrderDTO {
+	updated := order.Clone()
+	updated.Status = string(to)
+	s.cache.Set(updated.OrderUID, updated, cache.DefaultExpiration)
+	s.logger.Info("Order status changed",
+		slog.String("order_uid", updated.OrderUID),
+		slog.String("from", string(from)),
+		slog.String("to", string(to)))
+	return updated
+}
+
+func (s *orderService) ListOrders(ctx context.Context, filter repository.OrderFilter) (*repository.OrderPage, error) {
+	page, err := s.repo.ListOrders(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to list orders", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	s.logger.Debug("Orders listed", slog.Int("count", len(page.Orders)))
+
+	return page, nil
 }
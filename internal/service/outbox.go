@@ -0,0 +1,124 @@
+package service
+
+import (
+	"L0/internal/metrics"
+	"L0/internal/repository"
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	defaultOutboxBatchSize = 100
+	defaultOutboxInterval  = time.Second
+	defaultPublishTimeout  = 10 * time.Second
+)
+
+// OutboxOptions - параметры публикации outbox
+type OutboxOptions struct {
+	BatchSize int
+	// Interval - пауза перед следующей проверкой, если outbox пуст или публикация не удалась
+	Interval time.Duration
+	// PublishTimeout ограничивает публикацию пакета: всё это время открыта транзакция outbox
+	// и удерживается блокировка relay
+	PublishTimeout time.Duration
+}
+
+// OutboxRelay переносит события из outbox в брокер. Событие удаляется из outbox только после
+// успешной публикации, поэтому при сбоях оно может быть опубликовано повторно (at-least-once).
+type OutboxRelay struct {
+	outbox    repository.Outbox
+	publisher OutboxPublisher
+	logger    *slog.Logger
+	opts      OutboxOptions
+}
+
+func NewOutboxRelay(outbox repository.Outbox, publisher OutboxPublisher, logger *slog.Logger, opts OutboxOptions) *OutboxRelay {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultOutboxBatchSize
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultOutboxInterval
+	}
+	if opts.PublishTimeout <= 0 {
+		opts.PublishTimeout = defaultPublishTimeout
+	}
+	return &OutboxRelay{
+		outbox:    outbox,
+		publisher: publisher,
+		logger:    logger,
+		opts:      opts,
+	}
+}
+
+// Run публикует события до отмены ctx. Пока пакеты приходят полными, следующий
+// берётся сразу, иначе relay ждёт Interval.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	r.logger.Info("Outbox relay started",
+		slog.Int("batch_size", r.opts.BatchSize),
+		slog.Duration("interval", r.opts.Interval))
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Outbox relay stopped")
+			return
+		case <-timer.C:
+		}
+
+		n, err := r.PublishBatch(ctx)
+		r.observeLag(ctx)
+
+		next := r.opts.Interval
+		if err == nil && n == r.opts.BatchSize {
+			next = 0
+		}
+		timer.Reset(next)
+	}
+}
+
+// PublishBatch публикует один пакет событий и возвращает их число
+func (r *OutboxRelay) PublishBatch(ctx context.Context) (int, error) {
+	n, err := r.outbox.PublishOutbox(ctx, r.opts.BatchSize, r.publish)
+	if err != nil {
+		if ctx.Err() == nil {
+			metrics.OutboxPublishErrors.Inc()
+			r.logger.Error("Failed to publish outbox events", slog.String("error", err.Error()))
+		}
+		return 0, err
+	}
+
+	if n > 0 {
+		metrics.OutboxEventsPublished.Add(float64(n))
+		r.logger.Debug("Outbox events published", slog.Int("count", n))
+	}
+	return n, nil
+}
+
+// publish публикует пакет с ограничением по времени, чтобы недоступный брокер не держал транзакцию outbox
+func (r *OutboxRelay) publish(ctx context.Context, events []repository.OutboxEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, r.opts.PublishTimeout)
+	defer cancel()
+	return r.publisher.PublishEvents(ctx, events)
+}
+
+// observeLag обновляет метрики отставания outbox
+func (r *OutboxRelay) observeLag(ctx context.Context) {
+	pending, oldest, err := r.outbox.OutboxLag(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Warn("Failed to measure outbox lag", slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	age := 0.0
+	if !oldest.IsZero() {
+		age = max(time.Since(oldest).Seconds(), 0)
+	}
+	metrics.OutboxPendingEvents.Set(float64(pending))
+	metrics.OutboxOldestEventAge.Set(age)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"L0/internal/repository"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	defaultPendingSweepInterval  = time.Minute
+	defaultPendingSweepBatchSize = 100
+)
+
+// PendingSweepOptions - параметры обхода отложенных событий смены статуса
+type PendingSweepOptions struct {
+	// TTL - сколько хранить события заказов, которые так и не пришли; 0 - бессрочно
+	TTL       time.Duration
+	Interval  time.Duration
+	BatchSize int
+}
+
+// PendingStatusSweeper доводит отложенные события до заказов: применение сразу после сохранения
+// заказа могло не удаться, а другого повода у заказа может не быть. События заказов, которые
+// не пришли за TTL, удаляются, чтобы pending_status_events не рос бесконечно.
+type PendingStatusSweeper struct {
+	repo   repository.Repository
+	orders OrderService
+	logger *slog.Logger
+	opts   PendingSweepOptions
+}
+
+func NewPendingStatusSweeper(repo repository.Repository, orders OrderService, logger *slog.Logger, opts PendingSweepOptions) *PendingStatusSweeper {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultPendingSweepInterval
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultPendingSweepBatchSize
+	}
+	return &PendingStatusSweeper{
+		repo:   repo,
+		orders: orders,
+		logger: logger,
+		opts:   opts,
+	}
+}
+
+// Run выполняет обход раз в Interval до отмены ctx
+func (s *PendingStatusSweeper) Run(ctx context.Context) {
+	s.logger.Info("Pending status events sweeper started",
+		slog.Duration("interval", s.opts.Interval),
+		slog.Duration("ttl", s.opts.TTL))
+
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Pending status events sweeper stopped")
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to sweep pending status events", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// Sweep удаляет устаревшие отложенные события и применяет отложенные события сохранённых заказов.
+// Возвращает число заказов, события которых применены.
+func (s *PendingStatusSweeper) Sweep(ctx context.Context) (int, error) {
+	if s.opts.TTL > 0 {
+		purged, err := s.repo.PurgePendingStatusEvents(ctx, time.Now().Add(-s.opts.TTL))
+		if err != nil {
+			return 0, fmt.Errorf("failed to purge pending status events: %w", err)
+		}
+		if purged > 0 {
+			s.logger.Warn("Dropped pending status events of orders that never arrived",
+				slog.Int64("count", purged),
+				slog.Duration("ttl", s.opts.TTL))
+		}
+	}
+
+	orderUIDs, err := s.repo.OrdersWithPendingStatusEvents(ctx, s.opts.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find orders with pending status events: %w", err)
+	}
+
+	applied := 0
+	for _, orderUID := range orderUIDs {
+		if err := s.orders.ApplyPendingStatusEvents(ctx, orderUID); err != nil {
+			if ctx.Err() != nil {
+				return applied, ctx.Err()
+			}
+			s.logger.Error("Failed to apply pending status events",
+				slog.String("order_uid", orderUID),
+				slog.String("error", err.Error()))
+			continue
+		}
+		applied++
+	}
+
+	if applied > 0 {
+		s.logger.Info("Pending status events applied", slog.Int("orders", applied))
+	}
+	return applied, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// reconcileSkew - запас при сверке с БД: updated_at пишется часами другой реплики
+const reconcileSkew = time.Minute
+
+// SnapshotOptions - параметры снимков кэша на диске
+type SnapshotOptions struct {
+	Path string
+	// Interval - период записи снимка
+	Interval time.Duration
+	// MaxAge - снимок старше этого возраста при старте не используется, 0 - без ограничения
+	MaxAge time.Duration
+	// TTL восстановленных записей, как у WarmupOptions.TTL
+	TTL time.Duration
+}
+
+// CacheSnapshotter периодически сохраняет кэш в файл и восстанавливает его при старте,
+// чтобы не прогревать кэш из БД целиком
+type CacheSnapshotter struct {
+	cache  cache.Cache[string, *dto.OrderDTO]
+	warmer *CacheWarmer
+	logger *slog.Logger
+	opts   SnapshotOptions
+}
+
+func NewCacheSnapshotter(cache cache.Cache[string, *dto.OrderDTO], warmer *CacheWarmer, logger *slog.Logger, opts SnapshotOptions) *CacheSnapshotter {
+	return &CacheSnapshotter{
+		cache:  cache,
+		warmer: warmer,
+		logger: logger,
+		opts:   opts,
+	}
+}
+
+// Restore загружает снимок в кэш и возвращает момент, на который он сделан.
+// Повреждённый или устаревший снимок удаляется, и Restore возвращает false:
+// кэш нужно прогреть из БД целиком.
+func (s *CacheSnapshotter) Restore() (time.Time, bool) {
+	snapshot, err := cache.ReadSnapshot[*dto.OrderDTO](s.opts.Path, s.opts.MaxAge, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			s.logger.Info("Cache snapshot not found", slog.String("path", s.opts.Path))
+		case errors.Is(err, cache.ErrSnapshotCorrupted), errors.Is(err, cache.ErrSnapshotOutdated):
+			s.logger.Warn("Discarding cache snapshot",
+				slog.String("path", s.opts.Path),
+				slog.String("reason", err.Error()))
+			if err := os.Remove(s.opts.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				s.logger.Error("Failed to remove cache snapshot", slog.String("error", err.Error()))
+			}
+		default:
+			s.logger.Error("Failed to read cache snapshot", slog.String("error", err.Error()))
+		}
+		return time.Time{}, false
+	}
+
+	// Снимок записан от недавно использованных к давно использованным. Записи добавляются
+	// с конца, чтобы у LRU самые востребованные заказы снова вытеснялись последними.
+	for i := len(snapshot.Entries) - 1; i >= 0; i-- {
+		if entry := snapshot.Entries[i]; entry.Value != nil {
+			s.cache.Set(entry.Key, entry.Value, s.opts.TTL)
+		}
+	}
+
+	s.logger.Info("Cache restored from snapshot",
+		slog.Int("orders", len(snapshot.Entries)),
+		slog.Time("taken_at", snapshot.TakenAt))
+
+	return snapshot.TakenAt, true
+}
+
+// Reconcile догружает заказы, изменённые после снимка
+func (s *CacheSnapshotter) Reconcile(ctx context.Context, takenAt time.Time) (int, error) {
+	return s.warmer.Reconcile(ctx, takenAt.Add(-reconcileSkew))
+}
+
+// Save записывает снимок кэша
+func (s *CacheSnapshotter) Save() error {
+	start := time.Now()
+	n, err := cache.WriteSnapshot(s.opts.Path, s.cache, start)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Debug("Cache snapshot saved",
+		slog.String("path", s.opts.Path),
+		slog.Int("orders", n),
+		slog.Duration("elapsed", time.Since(start)))
+	return nil
+}
+
+// Run сохраняет снимок каждые Interval и последний раз - при отмене ctx
+func (s *CacheSnapshotter) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Save(); err != nil {
+				s.logger.Error("Failed to save cache snapshot on shutdown", slog.String("error", err.Error()))
+			}
+			return
+		case <-ticker.C:
+			if err := s.Save(); err != nil {
+				s.logger.Error("Failed to save cache snapshot", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
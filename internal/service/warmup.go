@@ -0,0 +1,124 @@
+package service
+
+import (
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"L0/internal/repository"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// progressInterval - как часто прогрев пишет в лог о прогрессе
+const progressInterval = 5 * time.Second
+
+// WarmupOptions ограничивает объём прогрева кэша
+type WarmupOptions struct {
+	BatchSize int
+	// MaxOrders - сколько самых свежих заказов загрузить, 0 - все
+	MaxOrders int
+	// Window - загружать только заказы с date_created не старше окна, 0 - без ограничения
+	Window time.Duration
+	// TTL прогретых записей: 0 - TTL кэша по умолчанию, отрицательное значение - без истечения
+	TTL time.Duration
+}
+
+// CacheWarmer загружает заказы из БД в кэш постранично, от новых к старым,
+// не держа в памяти больше одной страницы. Ограниченный кэш заполняется до ёмкости:
+// более старые заказы не вытесняют уже загруженные свежие.
+type CacheWarmer struct {
+	repo   repository.Repository
+	cache  cache.Cache[string, *dto.OrderDTO]
+	logger *slog.Logger
+	opts   WarmupOptions
+}
+
+func NewCacheWarmer(repo repository.Repository, cache cache.Cache[string, *dto.OrderDTO], logger *slog.Logger, opts WarmupOptions) *CacheWarmer {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = repository.DefaultPageSize
+	}
+	return &CacheWarmer{
+		repo:   repo,
+		cache:  cache,
+		logger: logger,
+		opts:   opts,
+	}
+}
+
+// Run прогревает кэш и возвращает количество загруженных заказов
+func (w *CacheWarmer) Run(ctx context.Context) (int, error) {
+	w.logger.Info("Cache warm-up started",
+		slog.Int("batch_size", w.opts.BatchSize),
+		slog.Int("max_orders", w.opts.MaxOrders),
+		slog.Duration("window", w.opts.Window))
+
+	return w.load(ctx, nil)
+}
+
+// Reconcile загружает в кэш заказы, изменённые после since, с теми же ограничениями, что и Run.
+// Используется после восстановления кэша из снимка.
+func (w *CacheWarmer) Reconcile(ctx context.Context, since time.Time) (int, error) {
+	w.logger.Info("Cache reconciliation started", slog.Time("updated_after", since))
+
+	return w.load(ctx, &since)
+}
+
+func (w *CacheWarmer) load(ctx context.Context, updatedAfter *time.Time) (int, error) {
+	start := time.Now()
+	filter := repository.OrderFilter{Limit: w.opts.BatchSize, UpdatedAfter: updatedAfter, Batch: true}
+	if w.opts.Window > 0 {
+		from := start.Add(-w.opts.Window)
+		filter.DateFrom = &from
+	}
+
+	// Сверка после снимка обновляет уже занятый кэш, поэтому записи в нём заменяются как обычно
+	backfill, bounded := w.cache.(cache.Backfiller[string, *dto.OrderDTO])
+	bounded = bounded && updatedAfter == nil
+
+	loaded := 0
+	full := false
+	lastProgress := start
+	for {
+		if w.opts.MaxOrders > 0 {
+			filter.Limit = min(w.opts.BatchSize, w.opts.MaxOrders-loaded)
+		}
+
+		page, err := w.repo.ListOrders(ctx, filter)
+		if err != nil {
+			return loaded, fmt.Errorf("failed to load orders batch: %w", err)
+		}
+
+		for i := range page.Orders {
+			order := page.Orders[i]
+			if !bounded {
+				w.cache.Set(order.OrderUID, &order, w.opts.TTL)
+			} else if full = !backfill.Backfill(order.OrderUID, &order, w.opts.TTL); full {
+				break
+			}
+			loaded++
+		}
+
+		if time.Since(lastProgress) >= progressInterval {
+			w.logger.Info("Cache loading in progress",
+				slog.Int("loaded", loaded),
+				slog.Duration("elapsed", time.Since(start)))
+			lastProgress = time.Now()
+		}
+
+		if full {
+			w.logger.Info("Cache is full, older orders are not loaded", slog.Int("loaded", loaded))
+			break
+		}
+		if page.NextCursor == "" || (w.opts.MaxOrders > 0 && loaded >= w.opts.MaxOrders) {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	w.logger.Info("Cache loading finished",
+		slog.Int("loaded", loaded),
+		slog.Duration("elapsed", time.Since(start)))
+
+	return loaded, nil
+}
@@ -0,0 +1,222 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Заголовки, по которым видно, откуда взято сообщение файлового источника
+const (
+	HeaderSourceFile = "source-file"
+	HeaderSourceLine = "source-line"
+)
+
+// NDJSONExtensions - расширения файлов, которые читаются из каталога
+var NDJSONExtensions = []string{".ndjson", ".jsonl"}
+
+const (
+	defaultPollInterval = time.Second
+	// maxPending ограничивает число прочитанных, но ещё не отданных сообщений
+	maxPending = 1000
+	// tailSettlePolls - сколько интервалов проверки файл не должен меняться,
+	// чтобы последняя строка без перевода строки считалась завершённой
+	tailSettlePolls = 10
+)
+
+// fileState - сколько файла уже прочитано
+type fileState struct {
+	offset int64
+	line   int
+	// tailSize - размер файла, при котором замечена незавершённая последняя строка
+	tailSize int64
+}
+
+// fileReader читает NDJSON из файла или каталога и следит за появлением новых строк и файлов.
+// Позиция хранится только в памяти: после перезапуска файлы читаются заново,
+// а повторные заказы и события отбрасываются как уже сохранённые.
+type fileReader struct {
+	path     string
+	topic    string
+	interval time.Duration
+
+	files   map[string]*fileState
+	pending []Message
+	offset  int64
+}
+
+// NewFileReader создает источник, читающий path - файл или каталог с *.ndjson и *.jsonl.
+// Новые строки и файлы проверяются раз в interval.
+func NewFileReader(path, topic string, interval time.Duration) (Reader, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("file source: %w", err)
+	}
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	return &fileReader{
+		path:     path,
+		topic:    topic,
+		interval: interval,
+		files:    make(map[string]*fileState),
+	}, nil
+}
+
+// Fetch возвращает сообщения, прочитанные из файлов по порядку; файлы каталога читаются по имени
+func (f *fileReader) Fetch(ctx context.Context) (Message, error) {
+	for len(f.pending) == 0 {
+		if err := f.poll(); err != nil {
+			return Message{}, err
+		}
+		if len(f.pending) > 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case <-time.After(f.interval):
+		}
+	}
+
+	m := f.pending[0]
+	f.pending = f.pending[1:]
+	return m, nil
+}
+
+// poll дочитывает новые строки всех файлов
+func (f *fileReader) poll() error {
+	paths, err := f.list()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := f.readFile(path); err != nil {
+			return err
+		}
+		if len(f.pending) >= maxPending {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (f *fileReader) list() ([]string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("file source: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{f.path}, nil
+	}
+
+	entries, err := os.ReadDir(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("file source: %w", err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && slices.Contains(NDJSONExtensions, filepath.Ext(entry.Name())) {
+			paths = append(paths, filepath.Join(f.path, entry.Name()))
+		}
+	}
+	// ReadDir возвращает записи, отсортированные по имени
+	return paths, nil
+}
+
+// readFile читает из файла завершённые строки после уже прочитанных.
+// Незавершённая последняя строка ждёт, пока файл перестанет меняться, остаток большого файла - следующего poll.
+func (f *fileReader) readFile(path string) error {
+	state, ok := f.files[path]
+	if !ok {
+		state = &fileState{}
+		f.files[path] = state
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			delete(f.files, path)
+			return nil
+		}
+		return fmt.Errorf("file source: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("file source: %w", err)
+	}
+	if info.Size() < state.offset {
+		// Файл перезаписан - читаем его сначала
+		*state = fileState{}
+	}
+	if _, err := file.Seek(state.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("file source: %w", err)
+	}
+
+	reader := bufio.NewReaderSize(file, 64<<10)
+	for len(f.pending) < maxPending {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(data) == 0 || !f.tailSettled(state, info) {
+				return nil
+			}
+			// Файл давно не менялся: строку без перевода строки больше никто не допишет
+		} else if err != nil {
+			return fmt.Errorf("file source: %w", err)
+		}
+		if len(data) > maxLineSize {
+			return fmt.Errorf("file source: %s:%d: line exceeds %d bytes", path, state.line+1, maxLineSize)
+		}
+
+		state.offset += int64(len(data))
+		state.line++
+		if data = trimLine(data); len(data) == 0 {
+			continue
+		}
+
+		f.offset++
+		f.pending = append(f.pending, Message{
+			Topic:  f.topic,
+			Offset: f.offset,
+			Value:  data,
+			Headers: []Header{
+				{Key: HeaderSourceFile, Value: []byte(path)},
+				{Key: HeaderSourceLine, Value: []byte(strconv.Itoa(state.line))},
+			},
+			Time: info.ModTime(),
+		})
+	}
+	return nil
+}
+
+// tailSettled сообщает, что незавершённая последняя строка не менялась tailSettlePolls интервалов
+func (f *fileReader) tailSettled(state *fileState, info os.FileInfo) bool {
+	if state.tailSize != info.Size() {
+		state.tailSize = info.Size()
+		return false
+	}
+	return time.Since(info.ModTime()) >= tailSettlePolls*f.interval
+}
+
+func (f *fileReader) Commit(context.Context, ...Message) error {
+	return nil
+}
+
+func (f *fileReader) Close() error {
+	return nil
+}
+
+// trimLine убирает перевод строки и пробелы по краям
+func trimLine(data []byte) []byte {
+	return bytes.TrimSpace(data)
+}
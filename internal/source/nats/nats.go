@@ -0,0 +1,186 @@
+// Package nats - источник сообщений из NATS JetStream
+package nats
+
+import (
+	"L0/internal/source"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// fetchBatch - сколько сообщений запрашивается у сервера за раз
+	fetchBatch = 100
+	// fetchWait - сколько ждать сообщений в одном запросе; ограничивает задержку остановки
+	fetchWait = time.Second
+
+	defaultAckWait = 2 * time.Minute
+)
+
+// Options - параметры подключения к JetStream
+type Options struct {
+	URL string
+	// Stream - поток JetStream, должен существовать и включать Subject
+	Stream  string
+	Subject string
+	// Durable - имя durable-консьюмера, который хранит позицию чтения на сервере
+	Durable string
+	// AckWait - сколько сервер ждёт подтверждения сообщения, прежде чем доставить его снова.
+	// Отсчёт начинается заново, когда сообщение выдаётся в обработку.
+	AckWait time.Duration
+	// MaxAckPending - сколько сообщений может быть выдано без подтверждения; не меньше размера пакета обработки
+	MaxAckPending int
+}
+
+// reader читает сообщения durable pull-консьюмера. Консьюмер подтверждает сообщения
+// политикой AckAll: подтверждение сообщения подтверждает и все предыдущие, как коммит смещения в Kafka.
+type reader struct {
+	conn     *nats.Conn
+	consumer jetstream.Consumer
+	subject  string
+	batch    int
+
+	buffer []jetstream.Msg
+
+	mu sync.Mutex
+	// unacked - выданные, но не подтверждённые сообщения по номеру в потоке
+	unacked map[uint64]jetstream.Msg
+}
+
+// NewReader подключается к серверу и создает или обновляет durable-консьюмер
+func NewReader(ctx context.Context, opts Options) (source.Reader, error) {
+	if opts.AckWait <= 0 {
+		opts.AckWait = defaultAckWait
+	}
+	if opts.MaxAckPending <= 0 {
+		opts.MaxAckPending = fetchBatch
+	}
+
+	conn, err := nats.Connect(opts.URL, nats.Name("l0"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	consumer, err := createConsumer(ctx, conn, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &reader{
+		conn:     conn,
+		consumer: consumer,
+		subject:  opts.Subject,
+		batch:    min(fetchBatch, opts.MaxAckPending),
+		unacked:  make(map[uint64]jetstream.Msg),
+	}, nil
+}
+
+func createConsumer(ctx context.Context, conn *nats.Conn, opts Options) (jetstream.Consumer, error) {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init JetStream: %w", err)
+	}
+
+	stream, err := js.Stream(ctx, opts.Stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open JetStream stream %q: %w", opts.Stream, err)
+	}
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       opts.Durable,
+		FilterSubject: opts.Subject,
+		AckPolicy:     jetstream.AckAllPolicy,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+		AckWait:       opts.AckWait,
+		MaxAckPending: opts.MaxAckPending,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream consumer %q: %w", opts.Durable, err)
+	}
+	return consumer, nil
+}
+
+func (r *reader) Fetch(ctx context.Context) (source.Message, error) {
+	for len(r.buffer) == 0 {
+		if err := ctx.Err(); err != nil {
+			return source.Message{}, err
+		}
+
+		batch, err := r.consumer.Fetch(r.batch, jetstream.FetchMaxWait(fetchWait))
+		if err != nil {
+			return source.Message{}, err
+		}
+		for msg := range batch.Messages() {
+			r.buffer = append(r.buffer, msg)
+		}
+		if err := batch.Error(); err != nil && !errors.Is(err, nats.ErrTimeout) {
+			return source.Message{}, err
+		}
+	}
+
+	msg := r.buffer[0]
+	r.buffer = r.buffer[1:]
+
+	meta, err := msg.Metadata()
+	if err != nil {
+		return source.Message{}, fmt.Errorf("invalid JetStream message: %w", err)
+	}
+
+	// Сообщение могло долго ждать в буфере: AckWait отсчитывается заново с начала обработки
+	if err := msg.InProgress(); err != nil {
+		return source.Message{}, fmt.Errorf("failed to extend JetStream ack deadline: %w", err)
+	}
+
+	r.mu.Lock()
+	r.unacked[meta.Sequence.Stream] = msg
+	r.mu.Unlock()
+
+	var headers []source.Header
+	for key, values := range msg.Headers() {
+		for _, value := range values {
+			headers = append(headers, source.Header{Key: key, Value: []byte(value)})
+		}
+	}
+
+	// Все сообщения - одна партиция: AckAll подтверждает их строго по порядку
+	return source.Message{
+		Topic:   msg.Subject(),
+		Offset:  int64(meta.Sequence.Stream),
+		Value:   msg.Data(),
+		Headers: headers,
+		Time:    meta.Timestamp,
+	}, nil
+}
+
+// Commit подтверждает последнее переданное сообщение, а с ним и все предыдущие
+func (r *reader) Commit(ctx context.Context, msgs ...source.Message) error {
+	var last uint64
+	for _, m := range msgs {
+		last = max(last, uint64(m.Offset))
+	}
+
+	r.mu.Lock()
+	msg, ok := r.unacked[last]
+	for seq := range r.unacked {
+		if seq <= last {
+			delete(r.unacked, seq)
+		}
+	}
+	r.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("message %d is not awaiting ack", last)
+	}
+	return msg.DoubleAck(ctx)
+}
+
+func (r *reader) Close() error {
+	r.conn.Close()
+	return nil
+}
@@ -0,0 +1,43 @@
+// Package source описывает источники входящих сообщений: Kafka, NATS JetStream,
+// NDJSON-файлы и stdin. Обработка сообщений от источника не зависит.
+package source
+
+import (
+	"context"
+	"time"
+)
+
+// Типы источников в конфиге
+const (
+	TypeKafka = "kafka"
+	TypeNATS  = "nats"
+	TypeFile  = "file"
+	TypeStdin = "stdin"
+)
+
+// Header - заголовок сообщения
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Message - сообщение источника. Partition и Offset задают порядок: сообщения одной партиции
+// обрабатываются по порядку, а коммит смещения подтверждает и все более ранние сообщения партиции.
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   []Header
+	Time      time.Time
+}
+
+// Reader читает сообщения с ручным подтверждением обработки
+type Reader interface {
+	// Fetch блокируется до следующего сообщения или отмены ctx
+	Fetch(ctx context.Context) (Message, error)
+	// Commit подтверждает обработку сообщений; для каждой партиции передаётся последнее из них
+	Commit(ctx context.Context, msgs ...Message) error
+	Close() error
+}
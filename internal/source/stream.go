@@ -0,0 +1,88 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// maxLineSize - максимальный размер одного сообщения в NDJSON
+const maxLineSize = 10 << 20 // 10MB
+
+type line struct {
+	data []byte
+	err  error
+}
+
+// streamReader читает NDJSON из потока, например stdin. Подтверждать нечего:
+// поток нельзя перечитать, поэтому Commit ничего не делает.
+type streamReader struct {
+	topic  string
+	lines  chan line
+	offset int64
+
+	once sync.Once
+	r    io.Reader
+}
+
+// NewStreamReader создает источник, читающий по сообщению из каждой непустой строки r.
+// Когда поток заканчивается, Fetch ждёт отмены контекста.
+func NewStreamReader(r io.Reader, topic string) Reader {
+	return &streamReader{
+		topic: topic,
+		lines: make(chan line),
+		r:     r,
+	}
+}
+
+func (s *streamReader) Fetch(ctx context.Context) (Message, error) {
+	// Чтение из потока нельзя прервать, поэтому оно идёт в отдельной горутине
+	s.once.Do(func() { go s.scan() })
+
+	for {
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case l, ok := <-s.lines:
+			if !ok {
+				<-ctx.Done()
+				return Message{}, ctx.Err()
+			}
+			if l.err != nil {
+				return Message{}, l.err
+			}
+			s.offset++
+			return Message{
+				Topic:  s.topic,
+				Offset: s.offset,
+				Value:  l.data,
+				Time:   time.Now(),
+			}, nil
+		}
+	}
+}
+
+func (s *streamReader) scan() {
+	defer close(s.lines)
+
+	scanner := bufio.NewScanner(s.r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+	for scanner.Scan() {
+		if data := trimLine(scanner.Bytes()); len(data) > 0 {
+			s.lines <- line{data: append([]byte(nil), data...)}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		s.lines <- line{err: err}
+	}
+}
+
+func (s *streamReader) Commit(context.Context, ...Message) error {
+	return nil
+}
+
+func (s *streamReader) Close() error {
+	return nil
+}
@@ -56,7 +56,7 @@ func (ov *OrderValidator) ValidateOrder(order *dto.OrderDTO) error {
 
 	// Структура
 	if err := ov.validator.Struct(order); err != nil {
-		return ov.convertValidationErrors(err)
+		return convertValidationErrors(err)
 	}
 
 	// Бизнес-логика
@@ -185,7 +185,7 @@ func (ov *OrderValidator) validateItemsConsistency(order *dto.OrderDTO) error {
 }
 
 // convertValidationErrors конвертирует ошибки валидатора в кастомный формат
-func (ov *OrderValidator) convertValidationErrors(err error) error {
+func convertValidationErrors(err error) error {
 	var vErrors ValidationErrors
 
 	var validationErrors validator.ValidationErrors
@@ -194,7 +194,7 @@ func (ov *OrderValidator) convertValidationErrors(err error) error {
 			vErrors = append(vErrors, ValidationError{
 				Field:   err.Field(),
 				Tag:     err.Tag(),
-				Message: ov.getErrorMessage(err),
+				Message: getErrorMessage(err),
 				Value:   fmt.Sprintf("%v", err.Value()),
 			})
 		}
@@ -204,7 +204,7 @@ func (ov *OrderValidator) convertValidationErrors(err error) error {
 }
 
 // getErrorMessage возвращает читаемые ошибки для пользователя
-func (ov *OrderValidator) getErrorMessage(err validator.FieldError) string {
+func getErrorMessage(err validator.FieldError) string {
 	switch err.Tag() {
 	case "required":
 		return "field is required"
@@ -0,0 +1,42 @@
+package validation
+
+import (
+	"L0/internal/kafka/dto"
+	"L0/internal/models"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// StatusEventValidator проверяет события смены статуса заказа
+type StatusEventValidator struct {
+	validator *validator.Validate
+}
+
+func NewStatusEventValidator() *StatusEventValidator {
+	return &StatusEventValidator{
+		validator: validator.New(),
+	}
+}
+
+// ValidateStatusEvent проверяет структуру события и то, что статус известен
+func (v *StatusEventValidator) ValidateStatusEvent(event *dto.StatusEventDTO) error {
+	if event == nil {
+		return errors.New("status event cannot be nil")
+	}
+
+	if err := v.validator.Struct(event); err != nil {
+		return convertValidationErrors(err)
+	}
+
+	if _, err := models.ParseOrderStatus(event.Status); err != nil {
+		return ValidationErrors{{
+			Field:   "status",
+			Tag:     "status",
+			Message: err.Error(),
+			Value:   event.Status,
+		}}
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package e2e_test
+
+import (
+	"L0/internal/app"
+	"L0/internal/cache"
+	"L0/internal/kafka"
+	"L0/internal/kafka/dto"
+	"L0/internal/service"
+	"L0/internal/source"
+	"L0/test/fakebroker"
+	"L0/test/mocks"
+	"L0/test/testutils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	ordersTopic = "orders"
+	dlqTopic    = "orders_dlq"
+	groupID     = "order-service"
+
+	waitTimeout = 5 * time.Second
+	waitTick    = 10 * time.Millisecond
+)
+
+// harness - сервис целиком: брокер в памяти -> consumer -> репозиторий -> кэш -> HTTP
+type harness struct {
+	t      *testing.T
+	broker *fakebroker.Broker
+	repo   *mocks.MockRepository
+	svc    service.OrderService
+	server *httptest.Server
+	logger *slog.Logger
+}
+
+func newHarness(t *testing.T, partitions int) *harness {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	broker := fakebroker.New()
+	broker.CreateTopic(ordersTopic, partitions)
+
+	repo := mocks.NewMockRepository()
+	// Как Postgres: повторное сохранение заказа нарушает уникальность order_uid
+	repo.RejectDuplicates = true
+	svc := service.NewOrderService(repo, cache.New[*dto.OrderDTO](time.Minute, time.Minute), logger)
+
+	router := chi.NewRouter()
+	app.RegisterRoutes(router, svc, logger)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return &harness{t: t, broker: broker, repo: repo, svc: svc, server: server, logger: logger}
+}
+
+// startConsumer подключает к группе нового участника и запускает на нём consumer.
+// Возвращённая функция останавливает consumer; reader при этом покидает группу.
+func (h *harness) startConsumer(opts kafka.ConsumerOptions) (*fakebroker.Reader, func()) {
+	reader := h.broker.NewReader(ordersTopic, groupID)
+	consumer := kafka.NewOrderConsumer(h.logger, h.broker.Publisher(dlqTopic), testutils.RetryPolicy(), opts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = consumer.ConsumeOrders(ctx, reader, h.svc)
+	}()
+
+	stop := func() {
+		cancel()
+		<-done
+	}
+	h.t.Cleanup(stop)
+	return reader, stop
+}
+
+// produce публикует count заказов с префиксом prefix и возвращает их order_uid
+func (h *harness) produce(prefix string, count int) []string {
+	uids := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		uid := fmt.Sprintf("%s_%03d", prefix, i)
+		data, err := json.Marshal(testutils.MinimalOrderFixture(uid))
+		require.NoError(h.t, err)
+		h.broker.Produce(ordersTopic, []byte(uid), data)
+		uids = append(uids, uid)
+	}
+	return uids
+}
+
+// waitCommitted ждёт, пока группа закоммитит все сообщения топика
+func (h *harness) waitCommitted() {
+	require.Eventually(h.t, func() bool {
+		return h.broker.Lag(ordersTopic, groupID) == 0
+	}, waitTimeout, waitTick, "consumer group did not commit all messages")
+}
+
+// getOrder запрашивает заказ через HTTP API
+func (h *harness) getOrder(uid string) (int, *dto.OrderDTO) {
+	resp, err := http.Get(h.server.URL + "/orders/" + uid)
+	require.NoError(h.t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	var order dto.OrderDTO
+	require.NoError(h.t, json.NewDecoder(resp.Body).Decode(&order))
+	return resp.StatusCode, &order
+}
+
+// assertServed проверяет, что каждый заказ отдаётся по HTTP
+func (h *harness) assertServed(uids []string) {
+	for _, uid := range uids {
+		status, order := h.getOrder(uid)
+		if assert.Equal(h.t, http.StatusOK, status, "order %s", uid) {
+			assert.Equal(h.t, uid, order.OrderUID)
+		}
+	}
+}
+
+// storedOrders возвращает число заказов в репозитории
+func (h *harness) storedOrders() int {
+	orders, err := h.repo.GetAllOrders(context.Background())
+	require.NoError(h.t, err)
+	return len(orders)
+}
+
+func TestIngest_OrdersReachHTTP(t *testing.T) {
+	cases := map[string]kafka.ConsumerOptions{
+		"single_worker": {Workers: 1},
+		"workers":       {Workers: 3},
+		"batches":       {Workers: 2, BatchSize: 10, BatchTimeout: 20 * time.Millisecond},
+	}
+	for name, opts := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newHarness(t, 4)
+			h.startConsumer(opts)
+
+			uids := h.produce("order", 40)
+			h.waitCommitted()
+
+			assert.Equal(t, 40, h.storedOrders())
+			h.assertServed(uids)
+			// Заказы, пришедшие из брокера, отдаются из кэша без обращения к БД
+			assert.Zero(t, h.repo.GetOrderByUIDCalls())
+		})
+	}
+}
+
+func TestIngest_DuplicateDelivery(t *testing.T) {
+	h := newHarness(t, 2)
+	h.broker.DuplicateNext(5)
+	h.startConsumer(kafka.ConsumerOptions{Workers: 2})
+
+	uids := h.produce("dup", 10)
+	h.waitCommitted()
+
+	assert.Equal(t, 15, h.broker.Delivered())
+	assert.Equal(t, 10, h.storedOrders(), "each order must be stored once")
+	assert.Empty(t, h.broker.Messages(dlqTopic), "duplicates must not go to the DLQ")
+	h.assertServed(uids)
+}
+
+func TestIngest_RedeliveryAfterFailedCommit(t *testing.T) {
+	h := newHarness(t, 1)
+	// Ни один коммит не проходит, пока ошибки не сняты
+	h.broker.FailNextCommits(1000, errors.New("coordinator not available"))
+	h.startConsumer(kafka.ConsumerOptions{Workers: 1})
+
+	uids := h.produce("redeliver", 10)
+	require.Eventually(t, func() bool {
+		return h.storedOrders() == 10
+	}, waitTimeout, waitTick)
+	assert.Equal(t, int64(10), h.broker.Lag(ordersTopic, groupID))
+
+	// После ребаланса чтение начинается с закоммиченного смещения: все сообщения приходят снова
+	h.broker.Rebalance(ordersTopic, groupID)
+	require.Eventually(t, func() bool {
+		return h.broker.Delivered() == 20
+	}, waitTimeout, waitTick)
+
+	h.broker.ClearFaults()
+	uids = append(uids, h.produce("redeliver_after", 1)...)
+	h.waitCommitted()
+
+	assert.Equal(t, 11, h.storedOrders())
+	assert.Empty(t, h.broker.Messages(dlqTopic))
+	h.assertServed(uids)
+}
+
+func TestIngest_GroupMembershipChanges(t *testing.T) {
+	h := newHarness(t, 4)
+	first, _ := h.startConsumer(kafka.ConsumerOptions{Workers: 2})
+	require.Len(t, first.Assigned(), 4)
+
+	uids := h.produce("before_join", 20)
+
+	// Второй участник забирает половину партиций посреди чтения
+	second, stopSecond := h.startConsumer(kafka.ConsumerOptions{Workers: 2})
+	assert.Len(t, first.Assigned(), 2)
+	assert.Len(t, second.Assigned(), 2)
+
+	uids = append(uids, h.produce("after_join", 20)...)
+	h.waitCommitted()
+
+	// После ухода второго участника его партиции возвращаются первому
+	stopSecond()
+	assert.Len(t, first.Assigned(), 4)
+
+	uids = append(uids, h.produce("after_leave", 20)...)
+	h.waitCommitted()
+
+	assert.Equal(t, 60, h.storedOrders())
+	h.assertServed(uids)
+}
+
+func TestIngest_BrokerDisconnect(t *testing.T) {
+	h := newHarness(t, 2)
+	h.startConsumer(kafka.ConsumerOptions{Workers: 2})
+
+	h.broker.Disconnect()
+	uids := h.produce("offline", 10)
+	assert.Never(t, func() bool {
+		return h.broker.Delivered() > 0
+	}, 200*time.Millisecond, waitTick, "nothing must be delivered while disconnected")
+
+	// Первое чтение после восстановления связи ещё падает, consumer должен его пережить
+	h.broker.FailNextFetches(1, errors.New("connection reset by peer"))
+	h.broker.Reconnect()
+	h.waitCommitted()
+
+	assert.Equal(t, 10, h.storedOrders())
+	h.assertServed(uids)
+}
+
+func TestIngest_InvalidMessageGoesToDLQ(t *testing.T) {
+	h := newHarness(t, 1)
+	h.startConsumer(kafka.ConsumerOptions{Workers: 1})
+
+	h.broker.Produce(ordersTopic, []byte("broken"), []byte("{not json"))
+	uids := h.produce("valid", 1)
+	h.waitCommitted()
+
+	dead := h.broker.Messages(dlqTopic)
+	require.Len(t, dead, 1)
+	assert.Equal(t, []byte("{not json"), dead[0].Value)
+	assert.NotEmpty(t, headerValue(t, dead[0], fakebroker.HeaderError), "DLQ message must carry the rejection cause")
+
+	status, _ := h.getOrder("broken")
+	assert.Equal(t, http.StatusNotFound, status)
+	h.assertServed(uids)
+}
+
+func headerValue(t *testing.T, m source.Message, key string) []byte {
+	for _, header := range m.Headers {
+		if header.Key == key {
+			return header.Value
+		}
+	}
+	t.Fatalf("header %q not found", key)
+	return nil
+}
@@ -0,0 +1,429 @@
+// Package fakebroker - брокер в памяти для сквозных тестов без Kafka.
+// Reader реализует source.Reader с семантикой группы потребителей Kafka: партиции
+// делятся между участниками группы, коммит сохраняет смещение группы, а после ребаланса
+// чтение продолжается с закоммиченного смещения. Хуки отказов имитируют разрыв
+// соединения, ошибки чтения и коммита и повторную доставку.
+package fakebroker
+
+import (
+	"L0/internal/source"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrDisconnected возвращается из Commit, пока брокер отключён
+	ErrDisconnected = errors.New("fakebroker: broker disconnected")
+	// ErrNotAssigned возвращается при коммите партиции, отобранной у участника ребалансом
+	ErrNotAssigned = errors.New("fakebroker: partition is not assigned to this member")
+	// ErrClosed возвращается из Fetch закрытого reader'а
+	ErrClosed = errors.New("fakebroker: reader closed")
+)
+
+type topic struct {
+	partitions [][]source.Message
+	groups     map[string]*group
+	next       int // партиция для следующего сообщения без ключа
+}
+
+type group struct {
+	// committed - следующее смещение для чтения по партициям
+	committed map[int]int64
+	members   []*Reader
+}
+
+// Broker хранит топики и группы потребителей. Все методы безопасны для параллельного вызова.
+type Broker struct {
+	mu     sync.Mutex
+	topics map[string]*topic
+	// changed закрывается при любом изменении, которого может ждать Fetch
+	changed chan struct{}
+
+	disconnected bool
+	fetchFaults  []error
+	commitFaults []error
+	duplicates   int
+	delivered    int
+}
+
+func New() *Broker {
+	return &Broker{
+		topics:  make(map[string]*topic),
+		changed: make(chan struct{}),
+	}
+}
+
+// CreateTopic создает топик с заданным числом партиций; повторный вызов ничего не меняет
+func (b *Broker) CreateTopic(name string, partitions int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.topicLocked(name, partitions)
+}
+
+func (b *Broker) topicLocked(name string, partitions int) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{
+			partitions: make([][]source.Message, max(partitions, 1)),
+			groups:     make(map[string]*group),
+		}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// Produce добавляет сообщение в топик, создавая его с одной партицией при необходимости.
+// Сообщения с одинаковым ключом попадают в одну партицию, без ключа - по кругу.
+func (b *Broker) Produce(topicName string, key, value []byte, headers ...source.Header) source.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.topicLocked(topicName, 1)
+	partition := t.next % len(t.partitions)
+	if key != nil {
+		h := fnv.New32a()
+		_, _ = h.Write(key)
+		partition = int(h.Sum32() % uint32(len(t.partitions)))
+	} else {
+		t.next++
+	}
+
+	m := source.Message{
+		Topic:     topicName,
+		Partition: partition,
+		Offset:    int64(len(t.partitions[partition])),
+		Key:       key,
+		Value:     value,
+		Headers:   headers,
+		Time:      time.Now(),
+	}
+	t.partitions[partition] = append(t.partitions[partition], m)
+	b.notifyLocked()
+	return m
+}
+
+// Messages возвращает все сообщения топика по партициям
+func (b *Broker) Messages(topicName string) []source.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var msgs []source.Message
+	if t, ok := b.topics[topicName]; ok {
+		for _, partition := range t.partitions {
+			msgs = append(msgs, partition...)
+		}
+	}
+	return msgs
+}
+
+// Committed возвращает следующее смещение для чтения группой по партициям
+func (b *Broker) Committed(topicName, groupID string) map[int]int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	committed := make(map[int]int64)
+	if t, ok := b.topics[topicName]; ok {
+		if g, ok := t.groups[groupID]; ok {
+			for p, offset := range g.committed {
+				committed[p] = offset
+			}
+		}
+	}
+	return committed
+}
+
+// Lag возвращает число незакоммиченных группой сообщений топика
+func (b *Broker) Lag(topicName, groupID string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t, ok := b.topics[topicName]
+	if !ok {
+		return 0
+	}
+	var committed map[int]int64
+	if g, ok := t.groups[groupID]; ok {
+		committed = g.committed
+	}
+	var lag int64
+	for p, partition := range t.partitions {
+		lag += int64(len(partition)) - committed[p]
+	}
+	return lag
+}
+
+// Delivered возвращает число сообщений, отданных из Fetch, включая повторные
+func (b *Broker) Delivered() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.delivered
+}
+
+// Disconnect имитирует потерю связи с брокером: Fetch ждёт восстановления, Commit возвращает ErrDisconnected
+func (b *Broker) Disconnect() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.disconnected = true
+}
+
+// Reconnect восстанавливает связь
+func (b *Broker) Reconnect() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.disconnected = false
+	b.notifyLocked()
+}
+
+// FailNextFetches заставляет следующие n вызовов Fetch вернуть err
+func (b *Broker) FailNextFetches(n int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := 0; i < n; i++ {
+		b.fetchFaults = append(b.fetchFaults, err)
+	}
+}
+
+// FailNextCommits заставляет следующие n вызовов Commit вернуть err, не сохраняя смещения
+func (b *Broker) FailNextCommits(n int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := 0; i < n; i++ {
+		b.commitFaults = append(b.commitFaults, err)
+	}
+}
+
+// DuplicateNext доставляет каждое из следующих n сообщений дважды подряд
+func (b *Broker) DuplicateNext(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.duplicates += n
+}
+
+// ClearFaults отменяет ещё не сработавшие ошибки чтения и коммита и повторные доставки
+func (b *Broker) ClearFaults() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.fetchFaults = nil
+	b.commitFaults = nil
+	b.duplicates = 0
+}
+
+// NewReader подключает нового участника группы groupID к топику и перераспределяет партиции
+func (b *Broker) NewReader(topicName, groupID string) *Reader {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.topicLocked(topicName, 1)
+	g, ok := t.groups[groupID]
+	if !ok {
+		g = &group{committed: make(map[int]int64)}
+		t.groups[groupID] = g
+	}
+
+	r := &Reader{broker: b, topic: t, group: g}
+	g.members = append(g.members, r)
+	b.rebalanceLocked(t, g)
+	return r
+}
+
+// Rebalance перераспределяет партиции группы. Все участники продолжают чтение
+// с закоммиченных смещений, поэтому незакоммиченные сообщения доставляются повторно.
+func (b *Broker) Rebalance(topicName, groupID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if t, ok := b.topics[topicName]; ok {
+		if g, ok := t.groups[groupID]; ok {
+			b.rebalanceLocked(t, g)
+		}
+	}
+}
+
+func (b *Broker) rebalanceLocked(t *topic, g *group) {
+	for _, m := range g.members {
+		m.assigned = nil
+		m.position = make(map[int]int64)
+		m.duplicate = nil
+	}
+	if len(g.members) > 0 {
+		for p := range t.partitions {
+			m := g.members[p%len(g.members)]
+			m.assigned = append(m.assigned, p)
+			m.position[p] = g.committed[p]
+		}
+	}
+	b.notifyLocked()
+}
+
+func (b *Broker) notifyLocked() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// Reader - участник группы потребителей, реализует source.Reader
+type Reader struct {
+	broker *Broker
+	topic  *topic
+	group  *group
+
+	// Поля ниже защищены broker.mu
+	assigned  []int
+	position  map[int]int64
+	next      int
+	duplicate *source.Message
+	closed    bool
+}
+
+// Assigned возвращает партиции, назначенные участнику
+func (r *Reader) Assigned() []int {
+	r.broker.mu.Lock()
+	defer r.broker.mu.Unlock()
+	return append([]int(nil), r.assigned...)
+}
+
+// Fetch отдаёт следующее сообщение из назначенных партиций, обходя их по кругу
+func (r *Reader) Fetch(ctx context.Context) (source.Message, error) {
+	b := r.broker
+	for {
+		b.mu.Lock()
+		if r.closed {
+			b.mu.Unlock()
+			return source.Message{}, ErrClosed
+		}
+		if len(b.fetchFaults) > 0 {
+			err := b.fetchFaults[0]
+			b.fetchFaults = b.fetchFaults[1:]
+			b.mu.Unlock()
+			return source.Message{}, err
+		}
+		if !b.disconnected {
+			if m, ok := r.nextLocked(); ok {
+				b.delivered++
+				b.mu.Unlock()
+				return m, nil
+			}
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return source.Message{}, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (r *Reader) nextLocked() (source.Message, bool) {
+	if r.duplicate != nil {
+		m := *r.duplicate
+		r.duplicate = nil
+		return m, true
+	}
+
+	for i := 0; i < len(r.assigned); i++ {
+		p := r.assigned[(r.next+i)%len(r.assigned)]
+		if r.position[p] >= int64(len(r.topic.partitions[p])) {
+			continue
+		}
+
+		m := r.topic.partitions[p][r.position[p]]
+		r.position[p]++
+		r.next = (r.next + i + 1) % len(r.assigned)
+		if r.broker.duplicates > 0 {
+			r.broker.duplicates--
+			r.duplicate = &m
+		}
+		return m, true
+	}
+	return source.Message{}, false
+}
+
+// Commit сохраняет смещения группы: коммит сообщения означает, что следующим будет прочитано m.Offset+1
+func (r *Reader) Commit(_ context.Context, msgs ...source.Message) error {
+	b := r.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if r.closed {
+		return ErrClosed
+	}
+	if b.disconnected {
+		return ErrDisconnected
+	}
+	if len(b.commitFaults) > 0 {
+		err := b.commitFaults[0]
+		b.commitFaults = b.commitFaults[1:]
+		return err
+	}
+
+	for _, m := range msgs {
+		if !r.ownsLocked(m.Partition) {
+			return fmt.Errorf("%w: partition %d", ErrNotAssigned, m.Partition)
+		}
+	}
+	for _, m := range msgs {
+		r.group.committed[m.Partition] = max(r.group.committed[m.Partition], m.Offset+1)
+	}
+	b.notifyLocked()
+	return nil
+}
+
+func (r *Reader) ownsLocked(partition int) bool {
+	for _, p := range r.assigned {
+		if p == partition {
+			return true
+		}
+	}
+	return false
+}
+
+// Close выводит участника из группы; его партиции переходят к остальным
+func (r *Reader) Close() error {
+	b := r.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	for i, m := range r.group.members {
+		if m == r {
+			r.group.members = append(r.group.members[:i], r.group.members[i+1:]...)
+			break
+		}
+	}
+	b.rebalanceLocked(r.topic, r.group)
+	return nil
+}
+
+// Publisher публикует отклонённые сообщения в топик брокера; подходит как DLQ consumer'а
+type Publisher struct {
+	broker *Broker
+	topic  string
+}
+
+// Publisher возвращает публикатор в топик topicName
+func (b *Broker) Publisher(topicName string) *Publisher {
+	return &Publisher{broker: b, topic: topicName}
+}
+
+// HeaderError - заголовок с причиной отказа, который добавляет Publisher
+const HeaderError = "error"
+
+func (p *Publisher) Publish(_ context.Context, msg source.Message, cause error) error {
+	headers := append(append([]source.Header(nil), msg.Headers...), source.Header{Key: HeaderError, Value: []byte(cause.Error())})
+	p.broker.Produce(p.topic, msg.Key, msg.Value, headers...)
+	return nil
+}
+
+func (p *Publisher) Close() error {
+	return nil
+}
+
+var _ source.Reader = (*Reader)(nil)
@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"L0/internal/config"
+	"L0/internal/kafka/dto"
+	"L0/internal/repository"
+	"L0/internal/repository/postgres"
+	"L0/test/testutils"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestPostgresCreateOrders(t *testing.T) {
+	cfg := config.MustLoad()
+
+	storage, err := postgres.New(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+	require.NoError(t, err)
+	defer func() { _ = storage.Close() }()
+
+	ctx := context.Background()
+	prefix := "batch_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	newOrder := func(i int) *dto.OrderDTO {
+		order := testutils.MinimalOrderFixture(prefix + "_" + strconv.Itoa(i))
+		order.TrackNumber = order.OrderUID
+		return order
+	}
+
+	orders := []*dto.OrderDTO{newOrder(1), newOrder(2), newOrder(3)}
+	created, err := storage.CreateOrders(ctx, orders)
+	require.NoError(t, err)
+	require.Len(t, created, 3)
+
+	for _, order := range orders {
+		stored, err := storage.GetOrderByUID(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assert.Equal(t, order.Delivery.Name, stored.Delivery.Name)
+		assert.Equal(t, order.Payment.Transaction, stored.Payment.Transaction)
+		assert.Len(t, stored.Items, len(order.Items))
+	}
+
+	// Дубликат откатывает всю пачку
+	_, err = storage.CreateOrders(ctx, []*dto.OrderDTO{newOrder(4), newOrder(1)})
+	require.ErrorIs(t, err, repository.ErrOrderExists)
+
+	_, err = storage.GetOrderByUID(ctx, prefix+"_4")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestPostgresCreateOrder_TrackNumberTaken(t *testing.T) {
+	cfg := config.MustLoad()
+
+	storage, err := postgres.New(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+	require.NoError(t, err)
+	defer func() { _ = storage.Close() }()
+
+	ctx := context.Background()
+	prefix := "track_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	first := testutils.MinimalOrderFixture(prefix + "_1")
+	first.TrackNumber = prefix
+	_, err = storage.CreateOrder(ctx, first)
+	require.NoError(t, err)
+
+	// Другой order_uid с занятым track_number - не дубликат, а постоянная ошибка
+	second := testutils.MinimalOrderFixture(prefix + "_2")
+	second.TrackNumber = prefix
+	_, err = storage.CreateOrder(ctx, second)
+	require.ErrorIs(t, err, repository.ErrTrackNumberTaken)
+	assert.ErrorIs(t, err, repository.ErrPermanent)
+	assert.NotErrorIs(t, err, repository.ErrOrderExists)
+}
@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"L0/internal/config"
+	"L0/internal/repository/postgres"
+	"L0/test/testutils"
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHandler struct {
+	changes chan postgres.OrderChange
+}
+
+func (h *recordingHandler) OrderChanged(change postgres.OrderChange) { h.changes <- change }
+func (h *recordingHandler) Resync()                                  {}
+
+func TestPostgresOrderChangeNotifications(t *testing.T) {
+	cfg := config.MustLoad()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	channel := "order_changes_test_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	storage, err := postgres.New(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+	require.NoError(t, err)
+	defer func() { _ = storage.Close() }()
+	storage.Notifier = postgres.NewNotifier(channel, "writer")
+
+	handler := &recordingHandler{changes: make(chan postgres.OrderChange, 1)}
+	listener := postgres.NewListener(
+		postgres.DSN(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName),
+		channel, "reader", time.Second, time.Minute, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = listener.Run(ctx, handler) }()
+	// LISTEN выполняется асинхронно, даём ему подписаться
+	time.Sleep(500 * time.Millisecond)
+
+	order := testutils.MinimalOrderFixture("notify_" + strconv.FormatInt(time.Now().UnixNano(), 10))
+	_, err = storage.CreateOrder(context.Background(), order)
+	require.NoError(t, err)
+
+	select {
+	case change := <-handler.changes:
+		assert.Equal(t, order.OrderUID, change.OrderUID)
+		assert.Equal(t, postgres.OpCreated, change.Op)
+		assert.Equal(t, "writer", change.Origin)
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification was not received")
+	}
+}
@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"L0/internal/config"
+	"L0/internal/kafka/dto"
+	"L0/internal/repository"
+	"L0/internal/repository/postgres"
+	"L0/test/testutils"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresOutbox(t *testing.T) {
+	cfg := config.MustLoad()
+
+	storage, err := postgres.New(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+	require.NoError(t, err)
+	defer func() { _ = storage.Close() }()
+	storage.WriteOutbox = true
+
+	ctx := context.Background()
+	orderUID := "outbox_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	order := testutils.MinimalOrderFixture(orderUID)
+	order.TrackNumber = orderUID
+
+	_, err = storage.CreateOrder(ctx, order)
+	require.NoError(t, err)
+
+	pending, oldest, err := storage.OutboxLag(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, pending)
+	assert.False(t, oldest.IsZero())
+
+	// Неудачная публикация оставляет события в outbox
+	_, err = storage.PublishOutbox(ctx, 1000, func(context.Context, []repository.OutboxEvent) error {
+		return errors.New("broker unavailable")
+	})
+	require.Error(t, err)
+
+	var found *repository.OutboxEvent
+	_, err = storage.PublishOutbox(ctx, 1000, func(_ context.Context, events []repository.OutboxEvent) error {
+		for i := range events {
+			if events[i].AggregateID == orderUID {
+				found = &events[i]
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotNil(t, found, "event for %s was not published", orderUID)
+	assert.Equal(t, dto.EventOrderPersisted, found.EventType)
+
+	var event dto.OrderPersistedEvent
+	require.NoError(t, json.Unmarshal(found.Payload, &event))
+	assert.Equal(t, dto.EventOrderPersisted+":"+orderUID, event.EventID)
+	assert.Equal(t, orderUID, event.OrderUID)
+	require.NotNil(t, event.Order)
+	assert.Equal(t, orderUID, event.Order.OrderUID)
+}
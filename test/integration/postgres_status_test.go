@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"L0/internal/config"
+	"L0/internal/models"
+	"L0/internal/repository"
+	"L0/internal/repository/postgres"
+	"L0/test/testutils"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresOrderStatusLifecycle(t *testing.T) {
+	cfg := config.MustLoad()
+
+	storage, err := postgres.New(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+	require.NoError(t, err)
+	defer func() { _ = storage.Close() }()
+
+	ctx := context.Background()
+	orderUID := "status_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	order := testutils.MinimalOrderFixture(orderUID)
+	order.TrackNumber = orderUID
+
+	created, err := storage.CreateOrder(ctx, order)
+	require.NoError(t, err)
+	assert.Equal(t, string(models.StatusCreated), created.Status)
+
+	err = storage.UpdateOrderStatus(ctx, repository.StatusChange{
+		OrderUID:  orderUID,
+		From:      models.StatusCreated,
+		To:        models.StatusPaid,
+		Reason:    "payment received",
+		ChangedAt: time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	stored, err := storage.GetOrderByUID(ctx, orderUID)
+	require.NoError(t, err)
+	assert.Equal(t, string(models.StatusPaid), stored.Status)
+
+	// Повтор с устаревшим исходным статусом не применяется
+	err = storage.UpdateOrderStatus(ctx, repository.StatusChange{
+		OrderUID:  orderUID,
+		From:      models.StatusCreated,
+		To:        models.StatusCancelled,
+		ChangedAt: time.Now().UTC(),
+	})
+	require.ErrorIs(t, err, repository.ErrStatusConflict)
+
+	var history []models.OrderStatusChange
+	require.NoError(t, storage.DB.
+		Joins("JOIN orders ON orders.id = order_status_history.order_id").
+		Where("orders.order_uid = ?", orderUID).
+		Find(&history).Error)
+	require.Len(t, history, 1)
+	assert.Equal(t, models.StatusCreated, history[0].FromStatus)
+	assert.Equal(t, models.StatusPaid, history[0].ToStatus)
+	assert.Equal(t, "payment received", history[0].Reason)
+}
+
+func TestPostgresSkippedStatusEvent(t *testing.T) {
+	cfg := config.MustLoad()
+
+	storage, err := postgres.New(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+	require.NoError(t, err)
+	defer func() { _ = storage.Close() }()
+
+	ctx := context.Background()
+	eventID := "skipped_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	processed, err := storage.StatusEventProcessed(ctx, eventID)
+	require.NoError(t, err)
+	assert.False(t, processed)
+
+	event := repository.StatusEvent{EventID: eventID, OrderUID: eventID, Status: models.StatusCreated}
+	require.NoError(t, storage.MarkStatusEventSkipped(ctx, event))
+	require.NoError(t, storage.MarkStatusEventSkipped(ctx, event), "repeated mark is ignored")
+
+	processed, err = storage.StatusEventProcessed(ctx, eventID)
+	require.NoError(t, err)
+	assert.True(t, processed)
+}
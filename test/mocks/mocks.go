@@ -3,10 +3,12 @@ package mocks
 import (
 	"L0/internal/cache"
 	"L0/internal/kafka/dto"
+	"L0/internal/models"
 	"L0/internal/repository"
 	"L0/internal/service"
 	"context"
-	"errors"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -15,20 +17,38 @@ import (
 
 // MockRepository - мок для repository.Repository
 type MockRepository struct {
-	mu     sync.RWMutex
-	orders map[string]*dto.OrderDTO
+	mu        sync.RWMutex
+	orders    map[string]*dto.OrderDTO
+	updatedAt map[string]time.Time
+	pending   map[string]repository.StatusEvent
+	// pendingReceived - время получения отложенных событий по event_id
+	pendingReceived map[string]time.Time
+	// skipped - события, не изменившие статус
+	skipped map[string]bool
 
 	// Для контроля поведения
 	ShouldFail         bool
 	FailError          error
 	CallsCreateOrder   int
+	CallsCreateOrders  int
 	CallsGetOrderByUID int
 	CallsGetAllOrders  int
+	CallsListOrders    int
+	// StatusChanges - применённые смены статуса
+	StatusChanges []repository.StatusChange
+	// GetGate, если задан, задерживает GetOrderByUID до получения значения или закрытия канала
+	GetGate chan struct{}
+	// RejectDuplicates - CreateOrder возвращает ErrOrderExists для уже сохранённого заказа, как Postgres
+	RejectDuplicates bool
 }
 
 func NewMockRepository() *MockRepository {
 	return &MockRepository{
-		orders: make(map[string]*dto.OrderDTO),
+		orders:          make(map[string]*dto.OrderDTO),
+		updatedAt:       make(map[string]time.Time),
+		pending:         make(map[string]repository.StatusEvent),
+		pendingReceived: make(map[string]time.Time),
+		skipped:         make(map[string]bool),
 	}
 }
 
@@ -42,17 +62,65 @@ func (m *MockRepository) CreateOrder(ctx context.Context, order *dto.OrderDTO) (
 	if m.ShouldFail {
 		return nil, m.FailError
 	}
+	if _, exists := m.orders[order.OrderUID]; exists && m.RejectDuplicates {
+		return nil, fmt.Errorf("%w: %s", repository.ErrOrderExists, order.OrderUID)
+	}
 
 	m.orders[order.OrderUID] = order
+	m.updatedAt[order.OrderUID] = time.Now()
 	return order, nil
 }
 
-func (m *MockRepository) GetOrderByUID(ctx context.Context, orderUID string) (*dto.OrderDTO, error) {
+// CreateOrders сохраняет заказы атомарно: при дубликате order_uid не сохраняется ни один
+func (m *MockRepository) CreateOrders(ctx context.Context, orders []*dto.OrderDTO) ([]*dto.OrderDTO, error) {
 	_ = ctx
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallsCreateOrders++
+
+	if m.ShouldFail {
+		return nil, m.FailError
+	}
+
+	seen := make(map[string]bool, len(orders))
+	for _, order := range orders {
+		if _, exists := m.orders[order.OrderUID]; exists || seen[order.OrderUID] {
+			return nil, fmt.Errorf("%w: %s", repository.ErrOrderExists, order.OrderUID)
+		}
+		seen[order.OrderUID] = true
+	}
+
+	now := time.Now()
+	for _, order := range orders {
+		m.orders[order.OrderUID] = order
+		m.updatedAt[order.OrderUID] = now
+	}
+	return orders, nil
+}
 
+// SetUpdatedAt задаёт время изменения заказа для фильтра UpdatedAfter
+func (m *MockRepository) SetUpdatedAt(orderUID string, updatedAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.updatedAt[orderUID] = updatedAt
+}
+
+func (m *MockRepository) GetOrderByUID(ctx context.Context, orderUID string) (*dto.OrderDTO, error) {
+	m.mu.Lock()
 	m.CallsGetOrderByUID++
+	m.mu.Unlock()
+
+	if m.GetGate != nil {
+		select {
+		case <-m.GetGate:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
 	if m.ShouldFail {
 		return nil, m.FailError
@@ -60,12 +128,19 @@ func (m *MockRepository) GetOrderByUID(ctx context.Context, orderUID string) (*d
 
 	order, exists := m.orders[orderUID]
 	if !exists {
-		return nil, errors.New("order not found")
+		return nil, gorm.ErrRecordNotFound
 	}
 
 	return order, nil
 }
 
+// GetOrderByUIDCalls возвращает число вызовов GetOrderByUID, безопасно при параллельных вызовах
+func (m *MockRepository) GetOrderByUIDCalls() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.CallsGetOrderByUID
+}
+
 func (m *MockRepository) GetAllOrders(ctx context.Context) ([]dto.OrderDTO, error) {
 	_ = ctx
 	m.mu.RLock()
@@ -85,23 +160,281 @@ func (m *MockRepository) GetAllOrders(ctx context.Context) ([]dto.OrderDTO, erro
 	return result, nil
 }
 
+func (m *MockRepository) ListOrders(ctx context.Context, filter repository.OrderFilter) (*repository.OrderPage, error) {
+	_ = ctx
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallsListOrders++
+
+	if m.ShouldFail {
+		return nil, m.FailError
+	}
+
+	return listOrders(m.orders, m.updatedAt, filter)
+}
+
+// UpdateOrderStatus меняет статус сохранённой копии заказа, проверяя текущий статус как БД
+func (m *MockRepository) UpdateOrderStatus(ctx context.Context, change repository.StatusChange) error {
+	_ = ctx
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ShouldFail {
+		return m.FailError
+	}
+
+	order, exists := m.orders[change.OrderUID]
+	if !exists {
+		return gorm.ErrRecordNotFound
+	}
+	if models.OrderStatus(order.Status) != change.From {
+		return repository.ErrStatusConflict
+	}
+	if change.EventID != "" && m.statusEventProcessed(change.EventID) {
+		return repository.ErrEventProcessed
+	}
+
+	updated := order.Clone()
+	updated.Status = string(change.To)
+	m.orders[change.OrderUID] = updated
+	m.updatedAt[change.OrderUID] = change.ChangedAt
+	m.StatusChanges = append(m.StatusChanges, change)
+	return nil
+}
+
+func (m *MockRepository) StatusEventProcessed(ctx context.Context, eventID string) (bool, error) {
+	_ = ctx
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.ShouldFail {
+		return false, m.FailError
+	}
+	return m.statusEventProcessed(eventID), nil
+}
+
+func (m *MockRepository) MarkStatusEventSkipped(ctx context.Context, event repository.StatusEvent) error {
+	_ = ctx
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ShouldFail {
+		return m.FailError
+	}
+	m.skipped[event.EventID] = true
+	return nil
+}
+
+func (m *MockRepository) statusEventProcessed(eventID string) bool {
+	if m.skipped[eventID] {
+		return true
+	}
+	for _, change := range m.StatusChanges {
+		if change.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MockRepository) SavePendingStatusEvent(ctx context.Context, event repository.StatusEvent) error {
+	_ = ctx
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ShouldFail {
+		return m.FailError
+	}
+	if _, exists := m.pending[event.EventID]; !exists {
+		m.pending[event.EventID] = event
+		m.pendingReceived[event.EventID] = time.Now()
+	}
+	return nil
+}
+
+func (m *MockRepository) PendingStatusEvents(ctx context.Context, orderUID string) ([]repository.StatusEvent, error) {
+	_ = ctx
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.ShouldFail {
+		return nil, m.FailError
+	}
+
+	var events []repository.StatusEvent
+	for _, event := range m.pending {
+		if event.OrderUID == orderUID {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].OccurredAt.Before(events[j].OccurredAt)
+	})
+	return events, nil
+}
+
+func (m *MockRepository) DeletePendingStatusEvent(ctx context.Context, eventID string) error {
+	_ = ctx
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ShouldFail {
+		return m.FailError
+	}
+	delete(m.pending, eventID)
+	delete(m.pendingReceived, eventID)
+	return nil
+}
+
+func (m *MockRepository) OrdersWithPendingStatusEvents(ctx context.Context, limit int) ([]string, error) {
+	_ = ctx
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.ShouldFail {
+		return nil, m.FailError
+	}
+
+	seen := make(map[string]bool)
+	var orderUIDs []string
+	for _, event := range m.pending {
+		if _, exists := m.orders[event.OrderUID]; !exists || seen[event.OrderUID] {
+			continue
+		}
+		seen[event.OrderUID] = true
+		orderUIDs = append(orderUIDs, event.OrderUID)
+	}
+	sort.Strings(orderUIDs)
+	if limit > 0 && len(orderUIDs) > limit {
+		orderUIDs = orderUIDs[:limit]
+	}
+	return orderUIDs, nil
+}
+
+func (m *MockRepository) PurgePendingStatusEvents(ctx context.Context, receivedBefore time.Time) (int64, error) {
+	_ = ctx
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ShouldFail {
+		return 0, m.FailError
+	}
+
+	var purged int64
+	for eventID, receivedAt := range m.pendingReceived {
+		if receivedAt.Before(receivedBefore) {
+			delete(m.pending, eventID)
+			delete(m.pendingReceived, eventID)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// SetPendingReceivedAt задаёт время получения отложенного события
+func (m *MockRepository) SetPendingReceivedAt(eventID string, receivedAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pendingReceived[eventID] = receivedAt
+}
+
+// PendingCount возвращает число отложенных событий смены статуса
+func (m *MockRepository) PendingCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.pending)
+}
+
 // Reset сбрасывает состояние мока
 func (m *MockRepository) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.orders = make(map[string]*dto.OrderDTO)
+	m.updatedAt = make(map[string]time.Time)
+	m.pending = make(map[string]repository.StatusEvent)
+	m.pendingReceived = make(map[string]time.Time)
+	m.skipped = make(map[string]bool)
 	m.ShouldFail = false
 	m.FailError = nil
 	m.CallsCreateOrder = 0
+	m.CallsCreateOrders = 0
 	m.CallsGetOrderByUID = 0
 	m.CallsGetAllOrders = 0
+	m.CallsListOrders = 0
+	m.StatusChanges = nil
+	m.RejectDuplicates = false
+}
+
+// MockOutbox - мок для repository.Outbox
+type MockOutbox struct {
+	mu     sync.Mutex
+	events []repository.OutboxEvent
+	nextID uint64
+
+	// LagErr возвращается из OutboxLag
+	LagErr error
+}
+
+func NewMockOutbox() *MockOutbox {
+	return &MockOutbox{}
+}
+
+// Add добавляет событие в outbox
+func (m *MockOutbox) Add(aggregateID string, createdAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	m.events = append(m.events, repository.OutboxEvent{
+		ID:          m.nextID,
+		AggregateID: aggregateID,
+		EventType:   "order.persisted",
+		Payload:     []byte(`{}`),
+		CreatedAt:   createdAt,
+	})
+}
+
+// Pending возвращает число неопубликованных событий
+func (m *MockOutbox) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.events)
+}
+
+func (m *MockOutbox) PublishOutbox(ctx context.Context, limit int, publish func(ctx context.Context, events []repository.OutboxEvent) error) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	batch := m.events[:min(limit, len(m.events))]
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	if err := publish(ctx, append([]repository.OutboxEvent(nil), batch...)); err != nil {
+		return 0, err
+	}
+	m.events = m.events[len(batch):]
+	return len(batch), nil
 }
 
-// MockCache - мок для cache.Cache
+func (m *MockOutbox) OutboxLag(_ context.Context) (int64, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.LagErr != nil {
+		return 0, time.Time{}, m.LagErr
+	}
+	if len(m.events) == 0 {
+		return 0, time.Time{}, nil
+	}
+	return int64(len(m.events)), m.events[0].CreatedAt, nil
+}
+
+var _ repository.Outbox = (*MockOutbox)(nil)
+
+// MockCache - мок для cache.Cache[string, *dto.OrderDTO]
 type MockCache struct {
 	mu   sync.RWMutex
-	data map[string]interface{}
+	data map[string]*dto.OrderDTO
 
 	// Для контроля поведения
 	ShouldFail  bool
@@ -110,21 +443,20 @@ type MockCache struct {
 	CallsDelete int
 
 	// Store - публичное поле для прямого доступа к данным в тестах
-	Store map[string]interface{}
+	Store map[string]*dto.OrderDTO
 }
 
 func NewMockCache() *MockCache {
 	c := &MockCache{
-		data:  make(map[string]interface{}),
-		Store: make(map[string]interface{}),
+		data: make(map[string]*dto.OrderDTO),
 	}
 	c.Store = c.data
 	return c
 }
 
-func (m *MockCache) Get(key string) (interface{}, bool) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+func (m *MockCache) Get(key string) (*dto.OrderDTO, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	m.CallsGet++
 
@@ -136,7 +468,7 @@ func (m *MockCache) Get(key string) (interface{}, bool) {
 	return value, exists
 }
 
-func (m *MockCache) Set(key string, value interface{}, ttl time.Duration) {
+func (m *MockCache) Set(key string, value *dto.OrderDTO, ttl time.Duration) {
 	_ = ttl
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -159,12 +491,66 @@ func (m *MockCache) Delete(key string) {
 	}
 }
 
+// GetOrLoad учитывается в CallsGet и, при промахе, в CallsSet
+func (m *MockCache) GetOrLoad(key string, ttl time.Duration, load func() (*dto.OrderDTO, error)) (*dto.OrderDTO, error) {
+	if value, found := m.Get(key); found {
+		return value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	m.Set(key, value, ttl)
+	return value, nil
+}
+
+func (m *MockCache) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data)
+}
+
+func (m *MockCache) Keys() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]string, 0, len(m.data))
+	for key := range m.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m *MockCache) Range(fn func(key string, value *dto.OrderDTO) bool) {
+	for _, key := range m.Keys() {
+		m.mu.RLock()
+		value, exists := m.data[key]
+		m.mu.RUnlock()
+
+		if exists && !fn(key, value) {
+			return
+		}
+	}
+}
+
+func (m *MockCache) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.data {
+		delete(m.data, key)
+	}
+}
+
 // Reset сбрасывает состояние мока
 func (m *MockCache) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.data = make(map[string]interface{})
+	m.data = make(map[string]*dto.OrderDTO)
 	m.Store = m.data // Синхронизируем Store с data
 	m.ShouldFail = false
 	m.CallsGet = 0
@@ -178,10 +564,22 @@ type MockOrderService struct {
 	orders map[string]*dto.OrderDTO
 
 	// Для контроля поведения
-	ShouldFail       bool
-	FailError        error
-	CallsGetOrder    int
-	CallsCreateOrder int
+	ShouldFail             bool
+	FailError              error
+	CallsGetOrder          int
+	CallsCreateOrder       int
+	CallsCreateOrders      int
+	CallsListOrders        int
+	CallsUpdateOrderStatus int
+	// StatusEvents - события, переданные в ApplyStatusEvent
+	StatusEvents []*dto.StatusEventDTO
+	// PendingApplied - заказы, переданные в ApplyPendingStatusEvents
+	PendingApplied []string
+	// ForgottenMissing - аргументы вызовов ForgetMissing
+	ForgottenMissing [][]string
+
+	// LastFilter - фильтр последнего вызова ListOrders
+	LastFilter repository.OrderFilter
 }
 
 func NewMockOrderService() *MockOrderService {
@@ -224,6 +622,96 @@ func (m *MockOrderService) CreateOrder(ctx context.Context, order *dto.OrderDTO)
 	return order, nil
 }
 
+func (m *MockOrderService) CreateOrders(ctx context.Context, orders []*dto.OrderDTO) ([]*dto.OrderDTO, error) {
+	_ = ctx
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallsCreateOrders++
+
+	if m.ShouldFail {
+		return nil, m.FailError
+	}
+
+	for _, order := range orders {
+		m.orders[order.OrderUID] = order
+	}
+	return orders, nil
+}
+
+func (m *MockOrderService) ListOrders(ctx context.Context, filter repository.OrderFilter) (*repository.OrderPage, error) {
+	_ = ctx
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallsListOrders++
+	m.LastFilter = filter
+
+	if m.ShouldFail {
+		return nil, m.FailError
+	}
+
+	return listOrders(m.orders, nil, filter)
+}
+
+func (m *MockOrderService) UpdateOrderStatus(ctx context.Context, orderUID string, status models.OrderStatus, reason string) (*dto.OrderDTO, error) {
+	_ = ctx
+	_ = reason
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallsUpdateOrderStatus++
+
+	if m.ShouldFail {
+		return nil, m.FailError
+	}
+
+	order, exists := m.orders[orderUID]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if err := models.ValidateTransition(models.OrderStatus(order.Status), status); err != nil {
+		return nil, err
+	}
+
+	updated := order.Clone()
+	updated.Status = string(status)
+	m.orders[orderUID] = updated
+	return updated, nil
+}
+
+func (m *MockOrderService) ApplyStatusEvent(ctx context.Context, event *dto.StatusEventDTO) error {
+	_ = ctx
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ShouldFail {
+		return m.FailError
+	}
+
+	m.StatusEvents = append(m.StatusEvents, event)
+	return nil
+}
+
+func (m *MockOrderService) ApplyPendingStatusEvents(ctx context.Context, orderUID string) error {
+	_ = ctx
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ShouldFail {
+		return m.FailError
+	}
+
+	m.PendingApplied = append(m.PendingApplied, orderUID)
+	return nil
+}
+
+func (m *MockOrderService) ForgetMissing(orderUIDs ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ForgottenMissing = append(m.ForgottenMissing, orderUIDs)
+}
+
 // AddOrder добавляет заказ в мок для тестирования
 func (m *MockOrderService) AddOrder(order *dto.OrderDTO) {
 	m.mu.Lock()
@@ -241,11 +729,79 @@ func (m *MockOrderService) Reset() {
 	m.FailError = nil
 	m.CallsGetOrder = 0
 	m.CallsCreateOrder = 0
+	m.CallsCreateOrders = 0
+	m.CallsListOrders = 0
+	m.CallsUpdateOrderStatus = 0
+	m.StatusEvents = nil
+	m.LastFilter = repository.OrderFilter{}
+}
+
+// listOrders фильтрует заказы мока и режет их на страницы.
+// Роль ID записи играет порядковый номер заказа, отсортированного по order_uid.
+// Без updatedAt фильтр UpdatedAfter не применяется.
+func listOrders(orders map[string]*dto.OrderDTO, updatedAt map[string]time.Time, filter repository.OrderFilter) (*repository.OrderPage, error) {
+	var afterID uint64
+	if filter.Cursor != "" {
+		id, err := repository.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		afterID = id
+	}
+
+	uids := make([]string, 0, len(orders))
+	for uid := range orders {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	limit := filter.PageSize()
+	page := &repository.OrderPage{Orders: make([]dto.OrderDTO, 0, limit)}
+
+	var lastID uint64
+	for i, uid := range uids {
+		id := uint64(i + 1)
+		if id <= afterID {
+			continue
+		}
+
+		o := orders[uid]
+		if filter.CustomerID != "" && o.CustomerID != filter.CustomerID ||
+			filter.DeliveryService != "" && o.DeliveryService != filter.DeliveryService ||
+			filter.Entry != "" && o.Entry != filter.Entry ||
+			filter.Locale != "" && o.Locale != filter.Locale ||
+			!inDateRange(o.DateCreated, filter.DateFrom, filter.DateTo) ||
+			updatedAt != nil && filter.UpdatedAfter != nil && !updatedAt[uid].After(*filter.UpdatedAfter) {
+			continue
+		}
+
+		if len(page.Orders) == limit {
+			page.NextCursor = repository.EncodeCursor(lastID)
+			break
+		}
+		page.Orders = append(page.Orders, *o)
+		lastID = id
+	}
+
+	return page, nil
 }
 
 // Проверяем, что моки реализуют интерфейсы
 var (
-	_ repository.Repository = (*MockRepository)(nil)
-	_ cache.Cache           = (*MockCache)(nil)
-	_ service.OrderService  = (*MockOrderService)(nil)
+	_ repository.Repository              = (*MockRepository)(nil)
+	_ cache.Cache[string, *dto.OrderDTO] = (*MockCache)(nil)
+	_ service.OrderService               = (*MockOrderService)(nil)
 )
+
+func inDateRange(dateCreated string, from, to *time.Time) bool {
+	if from == nil && to == nil {
+		return true
+	}
+
+	created, err := time.Parse(time.RFC3339, dateCreated)
+	if err != nil {
+		return false
+	}
+
+	return (from == nil || !created.Before(*from)) && (to == nil || created.Before(*to))
+}
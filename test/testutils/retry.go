@@ -0,0 +1,18 @@
+package testutils
+
+import (
+	"L0/internal/kafka"
+	"time"
+)
+
+// RetryPolicy возвращает политику повторов со значениями kafka.retry по умолчанию
+func RetryPolicy() kafka.RetryPolicy {
+	return kafka.RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		Fallback:       kafka.FallbackDLQ,
+	}
+}
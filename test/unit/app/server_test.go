@@ -0,0 +1,156 @@
+package app_test
+
+import (
+	"L0/internal/app"
+	"L0/internal/config"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPServer_Timeouts(t *testing.T) {
+	cfg := config.HTTPServer{
+		Address:        ":9090",
+		Timeout:        4 * time.Second,
+		WriteTimeout:   10 * time.Second,
+		IdleTimeout:    time.Minute,
+		MaxHeaderBytes: 4096,
+	}
+
+	server, reloader, err := app.NewHTTPServer(cfg, http.NotFoundHandler())
+	require.NoError(t, err)
+
+	assert.Nil(t, reloader)
+	assert.Nil(t, server.TLSConfig)
+	assert.Equal(t, ":9090", server.Addr)
+	assert.Equal(t, 4*time.Second, server.ReadTimeout, "falls back to timeout")
+	assert.Equal(t, 4*time.Second, server.ReadHeaderTimeout, "falls back to timeout")
+	assert.Equal(t, 10*time.Second, server.WriteTimeout)
+	assert.Equal(t, time.Minute, server.IdleTimeout)
+	assert.Equal(t, 4096, server.MaxHeaderBytes)
+}
+
+func TestNewHTTPServer_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeSelfSignedCert(t, certFile, keyFile, 1)
+
+	t.Run("enabled", func(t *testing.T) {
+		cfg := config.HTTPServer{TLS: config.TLS{Enabled: true, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3"}}
+
+		server, reloader, err := app.NewHTTPServer(cfg, http.NotFoundHandler())
+		require.NoError(t, err)
+		require.NotNil(t, reloader)
+		require.NotNil(t, server.TLSConfig)
+		assert.Equal(t, uint16(tls.VersionTLS13), server.TLSConfig.MinVersion)
+
+		cert, err := server.TLSConfig.GetCertificate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), leafSerial(t, cert))
+	})
+
+	t.Run("unsupported_min_version", func(t *testing.T) {
+		cfg := config.HTTPServer{TLS: config.TLS{Enabled: true, CertFile: certFile, KeyFile: keyFile, MinVersion: "2.0"}}
+
+		_, _, err := app.NewHTTPServer(cfg, http.NotFoundHandler())
+		assert.ErrorContains(t, err, "unsupported TLS version")
+	})
+
+	t.Run("missing_files", func(t *testing.T) {
+		cfg := config.HTTPServer{TLS: config.TLS{Enabled: true}}
+
+		_, _, err := app.NewHTTPServer(cfg, http.NotFoundHandler())
+		assert.ErrorContains(t, err, "cert_file and tls.key_file are required")
+	})
+}
+
+func TestCertReloader(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeSelfSignedCert(t, certFile, keyFile, 1)
+
+	reloader, err := app.NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+
+	t.Run("reload", func(t *testing.T) {
+		writeSelfSignedCert(t, certFile, keyFile, 2)
+		require.NoError(t, reloader.Reload())
+
+		cert, err := reloader.GetCertificate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), leafSerial(t, cert))
+	})
+
+	t.Run("broken_file_keeps_previous", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+		assert.Error(t, reloader.Reload())
+
+		cert, err := reloader.GetCertificate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), leafSerial(t, cert))
+	})
+
+	t.Run("sighup", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go reloader.WatchSignals(ctx, logger)
+		// Даём горутине подписаться на сигнал
+		time.Sleep(50 * time.Millisecond)
+
+		writeSelfSignedCert(t, certFile, keyFile, 3)
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+		assert.Eventually(t, func() bool {
+			cert, err := reloader.GetCertificate(nil)
+			return err == nil && leafSerial(t, cert) == 3
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func writeSelfSignedCert(t *testing.T, certFile, keyFile string, serial int64) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+}
+
+func leafSerial(t *testing.T, cert *tls.Certificate) int64 {
+	t.Helper()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.SerialNumber.Int64()
+}
@@ -2,6 +2,7 @@ package cache_test
 
 import (
 	"L0/internal/cache"
+	"L0/internal/kafka/dto"
 	"L0/test/testutils"
 	"sync"
 	"testing"
@@ -13,7 +14,7 @@ import (
 
 func TestGoCache_BasicOperations(t *testing.T) {
 	// Arrange
-	c := cache.New(5*time.Minute, 10*time.Minute)
+	c := cache.New[*dto.OrderDTO](5*time.Minute, 10*time.Minute)
 	testOrder := testutils.MinimalOrderFixture("test_order")
 
 	t.Run("set_and_get", func(t *testing.T) {
@@ -68,7 +69,7 @@ func TestGoCache_BasicOperations(t *testing.T) {
 }
 
 func TestGoCache_TTL(t *testing.T) {
-	c := cache.New(100*time.Millisecond, 50*time.Millisecond) // Короткие TTL для тестов
+	c := cache.New[*dto.OrderDTO](100*time.Millisecond, 50*time.Millisecond) // Короткие TTL для тестов
 	testOrder := testutils.MinimalOrderFixture("ttl_test")
 
 	t.Run("item_expires", func(t *testing.T) {
@@ -109,7 +110,7 @@ func TestGoCache_TTL(t *testing.T) {
 }
 
 func TestGoCache_ConcurrentAccess(t *testing.T) {
-	c := cache.New(5*time.Minute, 10*time.Minute)
+	c := cache.New[*dto.OrderDTO](5*time.Minute, 10*time.Minute)
 
 	t.Run("concurrent_writes", func(t *testing.T) {
 		const numGoroutines = 100
@@ -209,7 +210,7 @@ func TestGoCache_ConcurrentAccess(t *testing.T) {
 }
 
 func TestGoCache_DataTypes(t *testing.T) {
-	c := cache.New(5*time.Minute, 10*time.Minute)
+	c := cache.New[any](5*time.Minute, 10*time.Minute)
 
 	tests := []struct {
 		name  string
@@ -242,7 +243,7 @@ func TestGoCache_Performance(t *testing.T) {
 		t.Skip("Skipping performance test in short mode")
 	}
 
-	c := cache.New(5*time.Minute, 10*time.Minute)
+	c := cache.New[*dto.OrderDTO](5*time.Minute, 10*time.Minute)
 	const numOperations = 10000
 
 	t.Run("write_performance", func(t *testing.T) {
@@ -279,3 +280,83 @@ func TestGoCache_Performance(t *testing.T) {
 		assert.Less(t, duration, 500*time.Millisecond)
 	})
 }
+
+func TestGoCache_Stats(t *testing.T) {
+	c := cache.New[string](5*time.Minute, 10*time.Millisecond)
+
+	c.Set("hit", "value", 0)
+	c.Set("expiring", "value", 10*time.Millisecond)
+
+	c.Get("hit")
+	c.Get("hit")
+	c.Get("miss")
+
+	stats := c.Stats()
+	assert.Equal(t, 2, stats.Items)
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+
+	// Janitor удаляет просроченную запись
+	assert.Eventually(t, func() bool {
+		return c.Stats().Evictions == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, c.Stats().Items)
+}
+
+func TestGoCache_Enumeration(t *testing.T) {
+	c := cache.New[int](5*time.Minute, 10*time.Minute)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+
+	assert.Equal(t, 2, c.Len())
+	assert.ElementsMatch(t, []string{"a", "b"}, c.Keys())
+
+	sum := 0
+	c.Range(func(_ string, value int) bool {
+		sum += value
+		return true
+	})
+	assert.Equal(t, 3, sum)
+
+	value, err := c.GetOrLoad("c", 0, func() (int, error) { return 3, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 3, value)
+	assert.Equal(t, 3, c.Len())
+}
+
+func TestGoCache_FlushAndOldestEntry(t *testing.T) {
+	c := cache.New[int](5*time.Minute, 10*time.Minute)
+	c.Set("first", 1, 0)
+	time.Sleep(time.Millisecond)
+	c.Set("second", 2, 0)
+
+	key, _, ok := c.OldestEntry()
+	require.True(t, ok)
+	assert.Equal(t, "first", key)
+
+	c.Flush()
+	assert.Zero(t, c.Len())
+	assert.Equal(t, uint64(2), c.Stats().Evictions)
+	_, _, ok = c.OldestEntry()
+	assert.False(t, ok)
+}
+
+func TestGoCache_GetWithExpiration(t *testing.T) {
+	c := cache.New[int](time.Minute, 10*time.Minute)
+	c.Set("default", 1, cache.DefaultExpiration)
+	c.Set("forever", 2, cache.NoExpiration)
+
+	value, expiresAt, found := c.GetWithExpiration("default")
+	require.True(t, found)
+	assert.Equal(t, 1, value)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, time.Second)
+
+	_, expiresAt, found = c.GetWithExpiration("forever")
+	require.True(t, found)
+	assert.True(t, expiresAt.IsZero())
+
+	_, _, found = c.GetWithExpiration("missing")
+	assert.False(t, found)
+	assert.Equal(t, uint64(2), c.Stats().Hits)
+	assert.Equal(t, uint64(1), c.Stats().Misses)
+}
@@ -0,0 +1,280 @@
+package cache_test
+
+import (
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"L0/test/testutils"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU_BasicOperations(t *testing.T) {
+	c := cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{MaxEntries: 10})
+	order := testutils.MinimalOrderFixture("lru_order")
+
+	c.Set("key", order, cache.DefaultExpiration)
+	value, found := c.Get("key")
+	assert.True(t, found)
+	assert.Equal(t, order, value)
+
+	c.Delete("key")
+	value, found = c.Get("key")
+	assert.False(t, found)
+	assert.Nil(t, value)
+	assert.Zero(t, c.Bytes())
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	var evictedKeys []string
+	c := cache.NewLRU(cache.LRUOptions[string, string]{
+		MaxEntries: 3,
+		OnEvict: func(key string, _ string) {
+			evictedKeys = append(evictedKeys, key)
+		},
+	})
+
+	c.Set("a", "1", cache.NoExpiration)
+	c.Set("b", "2", cache.NoExpiration)
+	c.Set("c", "3", cache.NoExpiration)
+
+	// "a" становится самым свежим, вытесняется "b"
+	_, found := c.Get("a")
+	require.True(t, found)
+
+	c.Set("d", "4", cache.NoExpiration)
+
+	_, found = c.Get("b")
+	assert.False(t, found)
+	for _, key := range []string{"a", "c", "d"} {
+		_, found := c.Get(key)
+		assert.True(t, found, key)
+	}
+
+	assert.Equal(t, []string{"b"}, evictedKeys)
+	assert.Equal(t, uint64(1), c.Stats().Evictions)
+	assert.Equal(t, 3, c.Stats().Items)
+}
+
+func TestLRU_MemoryBudget(t *testing.T) {
+	order := testutils.MinimalOrderFixture("sizing")
+	orderSize := cache.EstimateSize(order)
+	require.Greater(t, orderSize, int64(0))
+	assert.Equal(t, order.Size(), orderSize, "orders estimate their own size")
+
+	// Бюджет примерно на три заказа с учётом накладных расходов
+	budget := 3 * (orderSize + 256)
+	c := cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{MaxBytes: budget})
+
+	for i := 0; i < 10; i++ {
+		uid := "order_" + strconv.Itoa(i)
+		c.Set(uid, testutils.MinimalOrderFixture(uid), cache.NoExpiration)
+		assert.LessOrEqual(t, c.Bytes(), budget)
+	}
+
+	assert.Equal(t, 3, c.Stats().Items)
+	_, found := c.Get("order_9")
+	assert.True(t, found, "latest entry is kept")
+	_, found = c.Get("order_0")
+	assert.False(t, found, "oldest entry is evicted")
+
+	t.Run("entry_larger_than_budget_is_not_cached", func(t *testing.T) {
+		small := cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{MaxBytes: 64})
+		small.Set("big", testutils.OrderFixture(), cache.NoExpiration)
+
+		_, found := small.Get("big")
+		assert.False(t, found)
+		assert.Zero(t, small.Bytes())
+	})
+}
+
+func TestLRU_TTL(t *testing.T) {
+	var evictedKeys []string
+	c := cache.NewLRU(cache.LRUOptions[string, string]{
+		DefaultTTL: 50 * time.Millisecond,
+		OnEvict: func(key string, _ string) {
+			evictedKeys = append(evictedKeys, key)
+		},
+	})
+
+	c.Set("default_ttl", "value", cache.DefaultExpiration)
+	c.Set("no_expiration", "value", cache.NoExpiration)
+	c.Set("custom_ttl", "value", time.Hour)
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, found := c.Get("default_ttl")
+	assert.False(t, found)
+	_, found = c.Get("no_expiration")
+	assert.True(t, found)
+	_, found = c.Get("custom_ttl")
+	assert.True(t, found)
+
+	assert.Equal(t, []string{"default_ttl"}, evictedKeys)
+	assert.Equal(t, uint64(2), c.Stats().Hits)
+	assert.Equal(t, uint64(1), c.Stats().Misses)
+}
+
+func TestLRU_CleanupExpired(t *testing.T) {
+	var evictedKeys []string
+	c := cache.NewLRU(cache.LRUOptions[string, string]{
+		MaxEntries:      3,
+		CleanupInterval: 20 * time.Millisecond,
+		OnEvict: func(key string, _ string) {
+			evictedKeys = append(evictedKeys, key)
+		},
+	})
+
+	c.Set("expired_1", "value", 10*time.Millisecond)
+	c.Set("expired_2", "value", 10*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+
+	// Просроченные записи освобождают место, а не вытесняют живые
+	for _, key := range []string{"a", "b", "c"} {
+		c.Set(key, "value", cache.NoExpiration)
+	}
+	assert.Equal(t, []string{"c", "b", "a"}, c.Keys())
+	assert.ElementsMatch(t, []string{"expired_1", "expired_2"}, evictedKeys)
+
+	c.Set("short", "value", 10*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, 2, c.Len(), "expired entries are not counted after cleanup")
+	assert.Equal(t, 2, c.Stats().Items)
+}
+
+func TestLRU_ConcurrentAccess(t *testing.T) {
+	c := cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{MaxEntries: 50})
+	order := testutils.MinimalOrderFixture("concurrent")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			key := "key_" + strconv.Itoa(id%75)
+			c.Set(key, order, cache.DefaultExpiration)
+			c.Get(key)
+			if id%10 == 0 {
+				c.Delete(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, c.Stats().Items, 50)
+}
+
+func TestLRU_Backfill(t *testing.T) {
+	c := cache.NewLRU(cache.LRUOptions[string, int]{MaxEntries: 3})
+	c.Set("hot", 0, cache.NoExpiration)
+
+	assert.True(t, c.Backfill("a", 1, cache.NoExpiration))
+	assert.True(t, c.Backfill("b", 2, cache.NoExpiration))
+	assert.False(t, c.Backfill("c", 3, cache.NoExpiration), "backfill must not evict")
+	assert.True(t, c.Backfill("a", 10, cache.NoExpiration), "replacing a key needs no room")
+
+	// Добавленные записи встают в конец очереди вытеснения
+	assert.Equal(t, []string{"hot", "b", "a"}, c.Keys())
+	assert.Zero(t, c.Stats().Evictions)
+
+	c.Set("d", 4, cache.NoExpiration)
+	assert.Equal(t, []string{"d", "hot", "b"}, c.Keys())
+}
+
+func TestLRU_Enumeration(t *testing.T) {
+	c := cache.NewLRU(cache.LRUOptions[string, int]{})
+	c.Set("a", 1, cache.NoExpiration)
+	c.Set("b", 2, cache.NoExpiration)
+	c.Set("expired", 3, time.Millisecond)
+	c.Set("c", 3, cache.NoExpiration)
+	time.Sleep(5 * time.Millisecond)
+
+	// Просроченная запись ещё не вычищена, но при обходе пропускается
+	assert.Equal(t, 4, c.Len())
+	assert.Equal(t, []string{"c", "b", "a"}, c.Keys())
+
+	var visited []string
+	c.Range(func(key string, value int) bool {
+		visited = append(visited, key)
+		// Обход идёт по снимку, поэтому изменение кэша из fn не блокируется
+		c.Delete(key)
+		return len(visited) < 2
+	})
+	assert.Equal(t, []string{"c", "b"}, visited)
+	assert.Equal(t, []string{"a"}, c.Keys())
+}
+
+func TestLRU_GetOrLoad(t *testing.T) {
+	c := cache.NewLRU(cache.LRUOptions[string, int]{})
+	loads := 0
+	load := func() (int, error) {
+		loads++
+		return 42, nil
+	}
+
+	value, err := c.GetOrLoad("key", cache.DefaultExpiration, load)
+	require.NoError(t, err)
+	assert.Equal(t, 42, value)
+
+	value, err = c.GetOrLoad("key", cache.DefaultExpiration, load)
+	require.NoError(t, err)
+	assert.Equal(t, 42, value)
+	assert.Equal(t, 1, loads)
+
+	t.Run("error_is_not_cached", func(t *testing.T) {
+		_, err := c.GetOrLoad("failing", cache.DefaultExpiration, func() (int, error) {
+			return 0, errors.New("load failed")
+		})
+		require.Error(t, err)
+
+		_, found := c.Get("failing")
+		assert.False(t, found)
+	})
+}
+
+func TestLRU_FlushAndOldestEntry(t *testing.T) {
+	c := cache.NewLRU(cache.LRUOptions[string, int]{})
+
+	_, _, ok := c.OldestEntry()
+	assert.False(t, ok)
+
+	c.Set("first", 1, cache.NoExpiration)
+	time.Sleep(time.Millisecond)
+	c.Set("second", 2, cache.NoExpiration)
+	// Чтение не меняет время записи
+	c.Get("first")
+
+	key, storedAt, ok := c.OldestEntry()
+	require.True(t, ok)
+	assert.Equal(t, "first", key)
+	assert.WithinDuration(t, time.Now(), storedAt, time.Second)
+
+	c.Flush()
+	assert.Zero(t, c.Len())
+	assert.Zero(t, c.Bytes())
+	_, found := c.Get("second")
+	assert.False(t, found)
+}
+
+func TestLRU_GetWithExpiration(t *testing.T) {
+	c := cache.NewLRU(cache.LRUOptions[string, int]{DefaultTTL: time.Minute})
+	c.Set("default", 1, cache.DefaultExpiration)
+	c.Set("forever", 2, cache.NoExpiration)
+
+	value, expiresAt, found := c.GetWithExpiration("default")
+	require.True(t, found)
+	assert.Equal(t, 1, value)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, time.Second)
+
+	_, expiresAt, found = c.GetWithExpiration("forever")
+	require.True(t, found)
+	assert.True(t, expiresAt.IsZero())
+
+	_, _, found = c.GetWithExpiration("missing")
+	assert.False(t, found)
+}
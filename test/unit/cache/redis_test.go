@@ -0,0 +1,184 @@
+package cache_test
+
+import (
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"L0/test/testutils"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedisCache(t *testing.T, opts cache.RedisOptions[*dto.OrderDTO]) (*cache.Redis[*dto.OrderDTO], *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	c := cache.NewRedis(client, opts)
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c, server
+}
+
+func TestRedis_BasicOperations(t *testing.T) {
+	c, server := newRedisCache(t, cache.RedisOptions[*dto.OrderDTO]{Prefix: "test:"})
+	order := testutils.MinimalOrderFixture("redis_order")
+
+	c.Set("redis_order", order, cache.DefaultExpiration)
+	assert.True(t, server.Exists("test:redis_order"))
+
+	value, found := c.Get("redis_order")
+	require.True(t, found)
+	assert.Equal(t, order, value)
+
+	c.Delete("redis_order")
+	value, found = c.Get("redis_order")
+	assert.False(t, found)
+	assert.Nil(t, value)
+	// Удаление отсутствующего ключа не считается вытеснением
+	c.Delete("redis_order")
+
+	stats := c.Stats()
+	assert.Equal(t, cache.ItemsUnknown, stats.Items, "stats must not scan the shared Redis")
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Evictions)
+}
+
+func TestRedis_SharedBetweenReplicas(t *testing.T) {
+	server := miniredis.RunT(t)
+	first := cache.NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}), cache.RedisOptions[*dto.OrderDTO]{})
+	second := cache.NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}), cache.RedisOptions[*dto.OrderDTO]{})
+
+	order := testutils.MinimalOrderFixture("shared")
+	first.Set("shared", order, cache.DefaultExpiration)
+
+	value, found := second.Get("shared")
+	require.True(t, found)
+	assert.Equal(t, order, value)
+}
+
+func TestRedis_TTL(t *testing.T) {
+	c, server := newRedisCache(t, cache.RedisOptions[*dto.OrderDTO]{DefaultTTL: time.Minute})
+	order := testutils.MinimalOrderFixture("ttl")
+
+	c.Set("default", order, cache.DefaultExpiration)
+	c.Set("explicit", order, time.Second)
+	c.Set("forever", order, cache.NoExpiration)
+
+	assert.Equal(t, time.Minute, server.TTL("default"))
+	assert.Equal(t, time.Second, server.TTL("explicit"))
+	assert.Zero(t, server.TTL("forever"))
+
+	server.FastForward(2 * time.Second)
+	_, found := c.Get("explicit")
+	assert.False(t, found)
+	_, found = c.Get("default")
+	assert.True(t, found)
+}
+
+func TestRedis_CorruptedValue(t *testing.T) {
+	var errs []string
+	c, server := newRedisCache(t, cache.RedisOptions[*dto.OrderDTO]{
+		OnError: func(op string, _ error) { errs = append(errs, op) },
+	})
+	require.NoError(t, server.Set("key", "not json"))
+
+	_, found := c.Get("key")
+
+	assert.False(t, found)
+	assert.Equal(t, []string{"decode"}, errs)
+}
+
+func TestRedis_Enumeration(t *testing.T) {
+	c, server := newRedisCache(t, cache.RedisOptions[*dto.OrderDTO]{Prefix: "test:"})
+	require.NoError(t, server.Set("foreign", "other service"))
+	for _, uid := range []string{"a", "b", "c"} {
+		c.Set(uid, testutils.MinimalOrderFixture(uid), cache.DefaultExpiration)
+	}
+
+	assert.Equal(t, 3, c.Len())
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, c.Keys())
+
+	seen := map[string]string{}
+	c.Range(func(key string, value *dto.OrderDTO) bool {
+		seen[key] = value.OrderUID
+		return true
+	})
+	assert.Equal(t, map[string]string{"a": "a", "b": "b", "c": "c"}, seen)
+}
+
+func TestRedis_EnumerationTruncated(t *testing.T) {
+	var errs []error
+	c, server := newRedisCache(t, cache.RedisOptions[*dto.OrderDTO]{
+		Prefix:  "test:",
+		OnError: func(_ string, err error) { errs = append(errs, err) },
+	})
+	c.Set("a", testutils.MinimalOrderFixture("a"), cache.DefaultExpiration)
+
+	server.SetError("LOADING Redis is loading the dataset in memory")
+	defer server.SetError("")
+
+	_, err := c.ScanKeys(context.Background())
+	assert.ErrorIs(t, err, cache.ErrKeysTruncated)
+
+	assert.Empty(t, c.Keys())
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], cache.ErrKeysTruncated, "partial key listing must be reported")
+}
+
+func TestRedis_L1(t *testing.T) {
+	l1 := cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{MaxEntries: 10})
+	c, server := newRedisCache(t, cache.RedisOptions[*dto.OrderDTO]{L1: l1, L1TTL: time.Minute})
+	order := testutils.MinimalOrderFixture("l1_order")
+
+	t.Run("read_through_fills_l1", func(t *testing.T) {
+		c.Set("l1_order", order, cache.DefaultExpiration)
+		l1.Delete("l1_order")
+
+		_, found := c.Get("l1_order")
+		require.True(t, found)
+
+		_, found = l1.Get("l1_order")
+		assert.True(t, found)
+	})
+
+	t.Run("serves_reads_while_redis_is_down", func(t *testing.T) {
+		server.Close()
+
+		value, found := c.Get("l1_order")
+		require.True(t, found)
+		assert.Equal(t, order, value)
+
+		other := testutils.MinimalOrderFixture("written_while_down")
+		c.Set("written_while_down", other, cache.DefaultExpiration)
+		value, found = c.Get("written_while_down")
+		require.True(t, found)
+		assert.Equal(t, other, value)
+	})
+
+	t.Run("delete_clears_l1", func(t *testing.T) {
+		c.Delete("l1_order")
+		_, found := l1.Get("l1_order")
+		assert.False(t, found)
+	})
+}
+
+func TestRedis_Flush(t *testing.T) {
+	l1 := cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{})
+	c, server := newRedisCache(t, cache.RedisOptions[*dto.OrderDTO]{Prefix: "test:", L1: l1})
+	require.NoError(t, server.Set("foreign", "other service"))
+	c.Set("a", testutils.MinimalOrderFixture("a"), cache.DefaultExpiration)
+	c.Set("b", testutils.MinimalOrderFixture("b"), cache.DefaultExpiration)
+
+	c.Flush()
+
+	assert.Zero(t, c.Len())
+	assert.Zero(t, l1.Len())
+	assert.True(t, server.Exists("foreign"))
+}
@@ -0,0 +1,112 @@
+package cache_test
+
+import (
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"L0/test/testutils"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestSnapshot(t *testing.T, takenAt time.Time) string {
+	t.Helper()
+
+	c := cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{})
+	for _, uid := range []string{"a", "b", "c"} {
+		c.Set(uid, testutils.MinimalOrderFixture(uid), cache.NoExpiration)
+	}
+
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	n, err := cache.WriteSnapshot(path, c, takenAt)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	return path
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	takenAt := time.Now().Truncate(time.Second)
+	path := writeTestSnapshot(t, takenAt)
+
+	snapshot, err := cache.ReadSnapshot[*dto.OrderDTO](path, time.Hour, time.Now())
+	require.NoError(t, err)
+
+	assert.True(t, takenAt.Equal(snapshot.TakenAt))
+	require.Len(t, snapshot.Entries, 3)
+	for _, entry := range snapshot.Entries {
+		assert.Equal(t, testutils.MinimalOrderFixture(entry.Key), entry.Value)
+	}
+
+	// Временные файлы не остаются рядом со снимком
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestSnapshot_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(data []byte) []byte
+		maxAge  time.Duration
+		wantErr error
+	}{
+		{
+			name: "flipped_byte",
+			corrupt: func(data []byte) []byte {
+				data[len(data)-10] ^= 0xff
+				return data
+			},
+			wantErr: cache.ErrSnapshotCorrupted,
+		},
+		{
+			name: "truncated",
+			corrupt: func(data []byte) []byte {
+				return data[:len(data)/2]
+			},
+			wantErr: cache.ErrSnapshotCorrupted,
+		},
+		{
+			name: "garbage",
+			corrupt: func([]byte) []byte {
+				return []byte("not a snapshot")
+			},
+			wantErr: cache.ErrSnapshotCorrupted,
+		},
+		{
+			name: "other_version",
+			corrupt: func(data []byte) []byte {
+				return bytes.Replace(data, []byte(`"version":1`), []byte(`"version":99`), 1)
+			},
+			wantErr: cache.ErrSnapshotOutdated,
+		},
+		{
+			name:    "too_old",
+			corrupt: func(data []byte) []byte { return data },
+			maxAge:  time.Minute,
+			wantErr: cache.ErrSnapshotOutdated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestSnapshot(t, time.Now().Add(-time.Hour))
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(path, tt.corrupt(data), 0o600))
+
+			_, err = cache.ReadSnapshot[*dto.OrderDTO](path, tt.maxAge, time.Now())
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := cache.ReadSnapshot[*dto.OrderDTO](filepath.Join(t.TempDir(), "none"), 0, time.Now())
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,141 @@
+package handlers_test
+
+import (
+	"L0/internal/app"
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"L0/internal/service"
+	"L0/test/mocks"
+	"L0/test/testutils"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const adminToken = "secret"
+
+func newAdminRouter(t *testing.T, repo *mocks.MockRepository, c cache.Cache[string, *dto.OrderDTO]) *chi.Mux {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	warmer := service.NewCacheWarmer(repo, c, logger, service.WarmupOptions{BatchSize: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	return app.NewCacheAdminRouter(service.NewCacheAdmin(ctx, c, warmer, logger), adminToken, logger)
+}
+
+func adminRequest(router http.Handler, method, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestAdminHandler_RequiresToken(t *testing.T) {
+	router := newAdminRouter(t, mocks.NewMockRepository(), mocks.NewMockCache())
+
+	for _, header := range []string{"", "Bearer wrong", adminToken} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/cache", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, header)
+	}
+}
+
+func TestAdminHandler_CacheStats(t *testing.T) {
+	c := cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{})
+	router := newAdminRouter(t, mocks.NewMockRepository(), c)
+
+	c.Set("first", testutils.MinimalOrderFixture("first"), cache.DefaultExpiration)
+	time.Sleep(time.Millisecond)
+	c.Set("second", testutils.MinimalOrderFixture("second"), cache.DefaultExpiration)
+	c.Get("second")
+	c.Get("missing")
+
+	recorder := adminRequest(router, http.MethodGet, "/admin/cache")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var stats service.CacheStats
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&stats))
+	assert.Equal(t, 2, stats.Size)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	require.NotNil(t, stats.OldestEntry)
+	assert.Equal(t, "first", stats.OldestEntry.OrderUID)
+	assert.False(t, stats.Rewarm.Running)
+}
+
+func TestAdminHandler_EvictAndFlush(t *testing.T) {
+	c := mocks.NewMockCache()
+	router := newAdminRouter(t, mocks.NewMockRepository(), c)
+	for _, uid := range []string{"a", "b", "c"} {
+		c.Store[uid] = testutils.MinimalOrderFixture(uid)
+	}
+
+	recorder := adminRequest(router, http.MethodDelete, "/admin/cache/a")
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.NotContains(t, c.Store, "a")
+	assert.Len(t, c.Store, 2)
+
+	recorder = adminRequest(router, http.MethodDelete, "/admin/cache")
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Empty(t, c.Store)
+}
+
+// enumerationCounter считает обращения к Len и Keys, которые у Redis означают полный SCAN
+type enumerationCounter struct {
+	cache.Cache[string, *dto.OrderDTO]
+	calls atomic.Int32
+}
+
+func (c *enumerationCounter) Len() int {
+	c.calls.Add(1)
+	return c.Cache.Len()
+}
+
+func (c *enumerationCounter) Keys() []string {
+	c.calls.Add(1)
+	return c.Cache.Keys()
+}
+
+func TestAdminHandler_Rewarm(t *testing.T) {
+	repo := mocks.NewMockRepository()
+	for _, uid := range []string{"a", "b", "c"} {
+		_, err := repo.CreateOrder(context.Background(), testutils.MinimalOrderFixture(uid))
+		require.NoError(t, err)
+	}
+	c := &enumerationCounter{Cache: cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{})}
+	router := newAdminRouter(t, repo, c)
+
+	recorder := adminRequest(router, http.MethodPost, "/admin/cache/rewarm")
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+	var status service.RewarmStatus
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&status))
+	assert.NotNil(t, status.StartedAt)
+	assert.Zero(t, c.calls.Load(), "starting a rewarm must not enumerate the cache")
+
+	require.Eventually(t, func() bool {
+		var stats service.CacheStats
+		recorder := adminRequest(router, http.MethodGet, "/admin/cache")
+		return json.NewDecoder(recorder.Body).Decode(&stats) == nil &&
+			!stats.Rewarm.Running && stats.Rewarm.FinishedAt != nil
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, 3, c.Len())
+}
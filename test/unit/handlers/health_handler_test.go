@@ -0,0 +1,113 @@
+package handlers_test
+
+import (
+	"L0/internal/handlers"
+	"L0/internal/health"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler_Liveness(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	checker := health.NewChecker(time.Second)
+	checker.Register("postgres", func(context.Context) error { return errors.New("connection refused") })
+
+	handler := handlers.NewHealthHandler(checker, logger)
+
+	recorder := httptest.NewRecorder()
+	handler.Liveness(recorder, httptest.NewRequest("GET", "/healthz", nil))
+
+	// Liveness не зависит от состояния зависимостей
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+}
+
+func TestHealthHandler_Readiness(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	cacheReady := health.NewFlag("cache warm-up in progress")
+	lastFetch := time.Now()
+	postgresErr := error(nil)
+
+	checker := health.NewChecker(100 * time.Millisecond)
+	checker.Register("cache", cacheReady.Check)
+	checker.Register("postgres", func(context.Context) error { return postgresErr })
+	checker.Register("kafka", health.Freshness(func() time.Time { return lastFetch }, time.Minute))
+
+	handler := handlers.NewHealthHandler(checker, logger)
+
+	readiness := func() (int, health.Report) {
+		recorder := httptest.NewRecorder()
+		handler.Readiness(recorder, httptest.NewRequest("GET", "/readyz", nil))
+
+		var report health.Report
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+		return recorder.Code, report
+	}
+
+	t.Run("cache_warming_up", func(t *testing.T) {
+		code, report := readiness()
+
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, health.StatusDown, report.Status)
+		assert.Equal(t, health.ComponentStatus{Status: health.StatusDown, Error: "cache warm-up in progress"}, report.Components["cache"])
+		assert.Equal(t, health.StatusOK, report.Components["postgres"].Status)
+		assert.Equal(t, health.StatusOK, report.Components["kafka"].Status)
+	})
+
+	cacheReady.MarkReady()
+
+	t.Run("ready", func(t *testing.T) {
+		code, report := readiness()
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, health.StatusOK, report.Status)
+		assert.Len(t, report.Components, 3)
+	})
+
+	t.Run("postgres_down", func(t *testing.T) {
+		postgresErr = errors.New("connection refused")
+		defer func() { postgresErr = nil }()
+
+		code, report := readiness()
+
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "connection refused", report.Components["postgres"].Error)
+		assert.Equal(t, health.StatusOK, report.Components["cache"].Status)
+	})
+
+	t.Run("kafka_stale", func(t *testing.T) {
+		lastFetch = time.Now().Add(-2 * time.Minute)
+		defer func() { lastFetch = time.Now() }()
+
+		code, report := readiness()
+
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, health.StatusDown, report.Components["kafka"].Status)
+		assert.Contains(t, report.Components["kafka"].Error, "no activity")
+	})
+
+	t.Run("check_timeout", func(t *testing.T) {
+		slow := health.NewChecker(10 * time.Millisecond)
+		slow.Register("postgres", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		recorder := httptest.NewRecorder()
+		handlers.NewHealthHandler(slow, logger).Readiness(recorder, httptest.NewRequest("GET", "/readyz", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "deadline exceeded")
+	})
+}
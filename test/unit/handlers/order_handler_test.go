@@ -2,10 +2,15 @@ package handlers_test
 
 import (
 	"L0/internal/handlers"
+	"L0/internal/repository"
+	"L0/internal/validation"
 	"L0/test/mocks"
 	"L0/test/testutils"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -218,3 +223,353 @@ func TestOrderHandler_ServeHTTP_ErrorHandling(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, recorder.Code)
 	})
 }
+
+func TestOrderHandler_List(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	tests := []struct {
+		name               string
+		query              string
+		setupMockService   func(*mocks.MockOrderService)
+		expectedStatusCode int
+		expectedResponse   string
+		expectServiceCall  bool
+	}{
+		{
+			name:  "success_with_filters",
+			query: "?customer_id=test_customer&entry=WBIL&limit=10&date_from=2021-11-26T06:22:19Z",
+			setupMockService: func(mockService *mocks.MockOrderService) {
+				mockService.AddOrder(testutils.MinimalOrderFixture("list_order"))
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   "list_order",
+			expectServiceCall:  true,
+		},
+		{
+			name:               "invalid_limit",
+			query:              "?limit=1000",
+			setupMockService:   func(mockService *mocks.MockOrderService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   "limit must be",
+		},
+		{
+			name:               "invalid_date",
+			query:              "?date_to=yesterday",
+			setupMockService:   func(mockService *mocks.MockOrderService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   "date_to must be in RFC3339 format",
+		},
+		{
+			name:               "inverted_date_range",
+			query:              "?date_from=2022-01-01T00:00:00Z&date_to=2021-01-01T00:00:00Z",
+			setupMockService:   func(mockService *mocks.MockOrderService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   "date_from must be before date_to",
+		},
+		{
+			name:               "invalid_cursor",
+			query:              "?cursor=not-a-cursor",
+			setupMockService:   func(mockService *mocks.MockOrderService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   "invalid cursor",
+		},
+		{
+			name:  "internal_server_error",
+			query: "",
+			setupMockService: func(mockService *mocks.MockOrderService) {
+				mockService.ShouldFail = true
+				mockService.FailError = errors.New("database connection failed")
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   "internal server error",
+			expectServiceCall:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockOrderService()
+			tt.setupMockService(mockService)
+
+			handler := handlers.NewOrderHandler(mockService, logger)
+
+			req := httptest.NewRequest("GET", "/orders"+tt.query, nil)
+			recorder := httptest.NewRecorder()
+
+			handler.List(recorder, req)
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedResponse)
+
+			if tt.expectServiceCall {
+				assert.Equal(t, 1, mockService.CallsListOrders)
+			} else {
+				assert.Equal(t, 0, mockService.CallsListOrders)
+			}
+		})
+	}
+}
+
+func TestOrderHandler_List_Pagination(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	mockService := mocks.NewMockOrderService()
+	for _, uid := range []string{"order_a", "order_b", "order_c"} {
+		mockService.AddOrder(testutils.MinimalOrderFixture(uid))
+	}
+
+	handler := handlers.NewOrderHandler(mockService, logger)
+
+	r := chi.NewRouter()
+	r.Get("/orders", handler.List)
+
+	var seen []string
+	cursor := ""
+	for i := 0; i < 3; i++ {
+		url := "/orders?limit=2"
+		if cursor != "" {
+			url += "&cursor=" + cursor
+		}
+
+		req := httptest.NewRequest("GET", url, nil)
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var page repository.OrderPage
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+		for _, o := range page.Orders {
+			seen = append(seen, o.OrderUID)
+		}
+
+		cursor = page.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{"order_a", "order_b", "order_c"}, seen)
+	assert.Equal(t, 2, mockService.CallsListOrders)
+}
+
+func TestOrderHandler_Create(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	tests := []struct {
+		name               string
+		body               func() []byte
+		setupMockService   func(*mocks.MockOrderService)
+		expectedStatusCode int
+		expectedResponse   string
+		expectServiceCall  bool
+	}{
+		{
+			name: "created",
+			body: func() []byte {
+				return mustMarshal(t, testutils.MinimalOrderFixture("http_order"))
+			},
+			setupMockService:   func(mockService *mocks.MockOrderService) {},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse:   "http_order",
+			expectServiceCall:  true,
+		},
+		{
+			name: "duplicate_order",
+			body: func() []byte {
+				return mustMarshal(t, testutils.MinimalOrderFixture("dup_order"))
+			},
+			setupMockService: func(mockService *mocks.MockOrderService) {
+				mockService.ShouldFail = true
+				mockService.FailError = fmt.Errorf("failed to create order: %w", repository.ErrOrderExists)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   "order already exists",
+			expectServiceCall:  true,
+		},
+		{
+			name: "track_number_taken",
+			body: func() []byte {
+				return mustMarshal(t, testutils.MinimalOrderFixture("other_order"))
+			},
+			setupMockService: func(mockService *mocks.MockOrderService) {
+				mockService.ShouldFail = true
+				mockService.FailError = fmt.Errorf("failed to create order: %w: %w", repository.ErrPermanent, repository.ErrTrackNumberTaken)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse:   `"field":"track_number"`,
+			expectServiceCall:  true,
+		},
+		{
+			name: "validation_failed",
+			body: func() []byte {
+				order := testutils.MinimalOrderFixture("invalid_order")
+				order.Payment.Amount = 1
+				order.Delivery.Email = "not-an-email"
+				return mustMarshal(t, order)
+			},
+			setupMockService:   func(mockService *mocks.MockOrderService) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse:   `"field":"Email"`,
+		},
+		{
+			name: "malformed_json",
+			body: func() []byte {
+				return []byte(`{"order_uid": `)
+			},
+			setupMockService:   func(mockService *mocks.MockOrderService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   "invalid request body",
+		},
+		{
+			name: "internal_server_error",
+			body: func() []byte {
+				return mustMarshal(t, testutils.MinimalOrderFixture("error_order"))
+			},
+			setupMockService: func(mockService *mocks.MockOrderService) {
+				mockService.ShouldFail = true
+				mockService.FailError = errors.New("database connection failed")
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   "internal server error",
+			expectServiceCall:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockOrderService()
+			tt.setupMockService(mockService)
+
+			handler := handlers.NewOrderHandler(mockService, logger)
+
+			req := httptest.NewRequest("POST", "/orders", bytes.NewReader(tt.body()))
+			recorder := httptest.NewRecorder()
+
+			handler.Create(recorder, req)
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedResponse)
+
+			if tt.expectServiceCall {
+				assert.Equal(t, 1, mockService.CallsCreateOrder)
+			} else {
+				assert.Equal(t, 0, mockService.CallsCreateOrder)
+			}
+		})
+	}
+}
+
+func TestOrderHandler_Create_ValidationErrorsJSON(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := handlers.NewOrderHandler(mocks.NewMockOrderService(), logger)
+
+	order := testutils.MinimalOrderFixture("invalid_order")
+	order.Payment.Transaction = "other"
+
+	req := httptest.NewRequest("POST", "/orders", bytes.NewReader(mustMarshal(t, order)))
+	recorder := httptest.NewRecorder()
+	handler.Create(recorder, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "application/json")
+
+	var resp struct {
+		Error  string                      `json:"error"`
+		Errors validation.ValidationErrors `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "payment.transaction", resp.Errors[0].Field)
+	assert.Equal(t, "consistency", resp.Errors[0].Tag)
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+func TestOrderHandler_UpdateStatus(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	tests := []struct {
+		name               string
+		orderStatus        string
+		body               string
+		setupMockService   func(*mocks.MockOrderService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:               "legal_transition",
+			orderStatus:        "created",
+			body:               `{"status": "paid", "reason": "payment received"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `"status":"paid"`,
+		},
+		{
+			name:               "illegal_transition",
+			orderStatus:        "created",
+			body:               `{"status": "delivered"}`,
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   "created -> delivered",
+		},
+		{
+			name:        "concurrent_change",
+			orderStatus: "created",
+			body:        `{"status": "paid"}`,
+			setupMockService: func(mockService *mocks.MockOrderService) {
+				mockService.ShouldFail = true
+				mockService.FailError = fmt.Errorf("failed to update order status: %w", repository.ErrStatusConflict)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   "changed concurrently",
+		},
+		{
+			name:               "unknown_status",
+			orderStatus:        "created",
+			body:               `{"status": "lost"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   "unknown order status",
+		},
+		{
+			name:               "malformed_json",
+			orderStatus:        "created",
+			body:               `{"status": `,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   "invalid request body",
+		},
+		{
+			name:               "order_not_found",
+			body:               `{"status": "paid"}`,
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   "order not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockOrderService()
+			if tt.orderStatus != "" {
+				order := testutils.MinimalOrderFixture("status_order")
+				order.Status = tt.orderStatus
+				mockService.AddOrder(order)
+			}
+			if tt.setupMockService != nil {
+				tt.setupMockService(mockService)
+			}
+
+			handler := handlers.NewOrderHandler(mockService, logger)
+			router := chi.NewRouter()
+			router.Patch("/orders/{order_uid}/status", handler.UpdateStatus)
+
+			req := httptest.NewRequest("PATCH", "/orders/status_order/status", bytes.NewReader([]byte(tt.body)))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedResponse)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package kafka_test
+
+import (
+	"L0/internal/kafka"
+	"L0/internal/kafka/dto"
+	"L0/internal/source"
+	"L0/test/testutils"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchSink сохраняет заказы пачками и отказывает в сохранении заказов из failUIDs
+type batchSink struct {
+	mu       sync.Mutex
+	failUIDs map[string]bool
+	batches  [][]string
+	singles  []string
+}
+
+func (s *batchSink) CreateOrder(_ context.Context, order *dto.OrderDTO) (*dto.OrderDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failUIDs[order.OrderUID] {
+		return nil, errors.New("permanent failure")
+	}
+	s.singles = append(s.singles, order.OrderUID)
+	return order, nil
+}
+
+func (s *batchSink) CreateOrders(_ context.Context, orders []*dto.OrderDTO) ([]*dto.OrderDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uids := make([]string, 0, len(orders))
+	for _, order := range orders {
+		if s.failUIDs[order.OrderUID] {
+			return nil, errors.New("permanent failure")
+		}
+		uids = append(uids, order.OrderUID)
+	}
+	s.batches = append(s.batches, uids)
+	return orders, nil
+}
+
+func (s *batchSink) saved() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.singles)
+	for _, batch := range s.batches {
+		n += len(batch)
+	}
+	return n
+}
+
+func orderMessages(uids ...string) []source.Message {
+	msgs := make([]source.Message, 0, len(uids))
+	for i, uid := range uids {
+		msgs = append(msgs, source.Message{
+			Topic:  "orders",
+			Offset: int64(i),
+			Value:  mustMarshalOrder(testutils.MinimalOrderFixture(uid)),
+		})
+	}
+	return msgs
+}
+
+func TestOrderBatchProcessor_ProcessBatch(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	processor := kafka.NewOrderBatchProcessor(logger, kafka.RetryPolicy{MaxAttempts: 1})
+
+	t.Run("saves_batch_in_one_call", func(t *testing.T) {
+		sink := &batchSink{}
+		errs := processor.ProcessBatch(context.Background(), orderMessages("a", "b", "c"), sink)
+
+		assert.Equal(t, []error{nil, nil, nil}, errs)
+		assert.Equal(t, [][]string{{"a", "b", "c"}}, sink.batches)
+		assert.Empty(t, sink.singles)
+	})
+
+	t.Run("invalid_message_is_rejected_without_breaking_batch", func(t *testing.T) {
+		sink := &batchSink{}
+		msgs := orderMessages("a", "b")
+		msgs = append(msgs, source.Message{Topic: "orders", Value: []byte("{broken")})
+
+		errs := processor.ProcessBatch(context.Background(), msgs, sink)
+
+		assert.NoError(t, errs[0])
+		assert.NoError(t, errs[1])
+		var rejectErr *kafka.RejectError
+		require.ErrorAs(t, errs[2], &rejectErr)
+		assert.Equal(t, kafka.ReasonInvalidFormat, rejectErr.Reason)
+		assert.Equal(t, [][]string{{"a", "b"}}, sink.batches)
+	})
+
+	t.Run("falls_back_to_single_messages_on_failure", func(t *testing.T) {
+		sink := &batchSink{failUIDs: map[string]bool{"bad": true}}
+		errs := processor.ProcessBatch(context.Background(), orderMessages("a", "bad", "c"), sink)
+
+		assert.NoError(t, errs[0])
+		var rejectErr *kafka.RejectError
+		require.ErrorAs(t, errs[1], &rejectErr)
+		assert.Equal(t, kafka.ReasonRetriesExhausted, rejectErr.Reason)
+		assert.NoError(t, errs[2])
+
+		assert.Empty(t, sink.batches)
+		assert.Equal(t, []string{"a", "c"}, sink.singles)
+	})
+}
+
+func TestOrderConsumer_Batches(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	const partitions, perPartition = 2, 25
+	reader := newFakeReader(partitions, perPartition)
+	sink := &batchSink{failUIDs: map[string]bool{"p1_007": true}}
+
+	consumer := kafka.NewOrderConsumer(logger, nil, kafka.RetryPolicy{MaxAttempts: 1, Fallback: kafka.FallbackSkip}, kafka.ConsumerOptions{
+		Workers:      partitions,
+		BatchSize:    10,
+		BatchTimeout: 20 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.ConsumeOrders(ctx, reader, sink) }()
+
+	require.Eventually(t, func() bool { return sink.saved() == partitions*perPartition-1 }, 5*time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool {
+		committed := reader.committed(t)
+		return committed[0] == perPartition-1 && committed[1] == perPartition-1
+	}, 5*time.Second, 5*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	require.NotEmpty(t, sink.batches)
+	for _, batch := range sink.batches {
+		assert.LessOrEqual(t, len(batch), 10)
+	}
+	// Пачка с плохим заказом сохранена по одному сообщению, без него самого
+	assert.NotContains(t, sink.singles, "p1_007")
+	assert.Contains(t, sink.singles, "p1_006")
+}
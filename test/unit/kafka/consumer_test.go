@@ -3,11 +3,15 @@ package kafka_test
 import (
 	"L0/internal/kafka"
 	"L0/internal/kafka/dto"
+	"L0/internal/repository"
+	"L0/internal/service"
+	"L0/internal/source"
 	"L0/test/mocks"
 	"L0/test/testutils"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"testing"
@@ -58,13 +62,8 @@ func TestOrderMessageProcessor_ProcessMessage(t *testing.T) {
 			name:        "duplicate_order_handled",
 			messageData: mustMarshalOrder(testutils.MinimalOrderFixture("duplicate_order")),
 			setupRepo: func(repo *mocks.MockRepository) {
-				pgErr := &pq.Error{
-					Code:       "23505", // unique_violation
-					Table:      "orders",
-					Constraint: "orders_order_uid_key",
-				}
 				repo.ShouldFail = true
-				repo.FailError = pgErr
+				repo.FailError = fmt.Errorf("%w: %s", repository.ErrOrderExists, "duplicate_order")
 			},
 			expectedError:  "",
 			expectRepoCall: true,
@@ -76,7 +75,7 @@ func TestOrderMessageProcessor_ProcessMessage(t *testing.T) {
 			mockRepo := mocks.NewMockRepository()
 			tt.setupRepo(mockRepo)
 
-			processor := kafka.NewOrderMessageProcessor(logger)
+			processor := kafka.NewOrderMessageProcessor(logger, testutils.RetryPolicy())
 
 			ctx := context.Background()
 			if tt.name == "repo_error_retry" {
@@ -108,7 +107,7 @@ func TestOrderMessageProcessor_ProcessMessage(t *testing.T) {
 
 func TestOrderMessageProcessor_ProcessMessage_ErrorHandling(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	processor := kafka.NewOrderMessageProcessor(logger)
+	processor := kafka.NewOrderMessageProcessor(logger, testutils.RetryPolicy())
 
 	t.Run("context_cancellation", func(t *testing.T) {
 		mockRepo := mocks.NewMockRepository()
@@ -152,24 +151,24 @@ func TestOrderConsumer_ConsumeOrders(t *testing.T) {
 
 	t.Run("context_cancellation", func(t *testing.T) {
 		mockRepo := mocks.NewMockRepository()
-		consumer := kafka.NewOrderConsumer(logger)
+		consumer := kafka.NewOrderConsumer(logger, nil, testutils.RetryPolicy(), kafka.ConsumerOptions{})
 
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
 
-		err := consumer.ConsumeOrders(ctx, []string{"localhost:9092"}, "test-topic", "test-group", mockRepo)
+		err := consumer.ConsumeOrders(ctx, kafka.NewReader([]string{"localhost:9092"}, "test-topic", "test-group"), mockRepo)
 
 		assert.NoError(t, err)
 	})
 
 	t.Run("timeout_context", func(t *testing.T) {
 		mockRepo := mocks.NewMockRepository()
-		consumer := kafka.NewOrderConsumer(logger)
+		consumer := kafka.NewOrderConsumer(logger, nil, testutils.RetryPolicy(), kafka.ConsumerOptions{})
 
 		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
 		defer cancel()
 
-		err := consumer.ConsumeOrders(ctx, []string{"nonexistent:9092"}, "test-topic", "test-group", mockRepo)
+		err := consumer.ConsumeOrders(ctx, kafka.NewReader([]string{"nonexistent:9092"}, "test-topic", "test-group"), mockRepo)
 
 		if err != nil {
 			assert.Error(t, err)
@@ -186,14 +185,14 @@ func TestOrderConsumer_Integration(t *testing.T) {
 
 	t.Run("consumer_with_mock_repo", func(t *testing.T) {
 		mockRepo := mocks.NewMockRepository()
-		consumer := kafka.NewOrderConsumer(logger)
+		consumer := kafka.NewOrderConsumer(logger, nil, testutils.RetryPolicy(), kafka.ConsumerOptions{})
 
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 		defer cancel()
 
 		done := make(chan error, 1)
 		go func() {
-			err := consumer.ConsumeOrders(ctx, []string{"localhost:9092"}, "test-topic", "test-group", mockRepo)
+			err := consumer.ConsumeOrders(ctx, kafka.NewReader([]string{"localhost:9092"}, "test-topic", "test-group"), mockRepo)
 			done <- err
 		}()
 
@@ -210,6 +209,206 @@ func TestOrderConsumer_Integration(t *testing.T) {
 	})
 }
 
+func TestOrderMessageProcessor_ThroughOrderService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	processor := kafka.NewOrderMessageProcessor(logger, testutils.RetryPolicy())
+
+	t.Run("persisted_order_is_cached", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		mockCache := mocks.NewMockCache()
+		orderService := service.NewOrderService(mockRepo, mockCache, logger)
+
+		order := testutils.MinimalOrderFixture("cached_order")
+		err := processor.ProcessMessage(context.Background(), mustMarshalOrder(order), orderService)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, mockRepo.CallsCreateOrder)
+		assert.Equal(t, 1, mockCache.CallsSet)
+		require.Contains(t, mockCache.Store, "cached_order")
+
+		// Заказ отдаётся из кэша без обращения к БД
+		result, err := orderService.GetOrder(context.Background(), "cached_order")
+		require.NoError(t, err)
+		assert.Equal(t, order.OrderUID, result.OrderUID)
+		assert.Equal(t, 0, mockRepo.CallsGetOrderByUID)
+	})
+
+	t.Run("duplicate_is_not_cached", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		mockRepo.ShouldFail = true
+		mockRepo.FailError = repository.ErrOrderExists
+		mockCache := mocks.NewMockCache()
+		orderService := service.NewOrderService(mockRepo, mockCache, logger)
+
+		err := processor.ProcessMessage(context.Background(), mustMarshalOrder(testutils.MinimalOrderFixture("dup")), orderService)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, mockCache.CallsSet)
+	})
+
+	t.Run("invalid_order_is_not_cached", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		mockCache := mocks.NewMockCache()
+		orderService := service.NewOrderService(mockRepo, mockCache, logger)
+
+		order := testutils.MinimalOrderFixture("invalid")
+		order.Items = nil
+
+		err := processor.ProcessMessage(context.Background(), mustMarshalOrder(order), orderService)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, mockRepo.CallsCreateOrder)
+		assert.Empty(t, mockCache.Store)
+	})
+}
+
+func TestOrderMessageProcessor_RejectError(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	processor := kafka.NewOrderMessageProcessor(logger, testutils.RetryPolicy())
+
+	t.Run("invalid_format", func(t *testing.T) {
+		err := processor.ProcessMessage(context.Background(), []byte(`{"invalid": json`), mocks.NewMockRepository())
+
+		var rejectErr *kafka.RejectError
+		require.True(t, errors.As(err, &rejectErr))
+		assert.Equal(t, kafka.ReasonInvalidFormat, rejectErr.Reason)
+		assert.Empty(t, rejectErr.ValidationErrors())
+	})
+
+	t.Run("validation_failed", func(t *testing.T) {
+		order := testutils.MinimalOrderFixture("invalid_order")
+		order.Payment.Transaction = "other"
+
+		err := processor.ProcessMessage(context.Background(), mustMarshalOrder(order), mocks.NewMockRepository())
+
+		var rejectErr *kafka.RejectError
+		require.True(t, errors.As(err, &rejectErr))
+		assert.Equal(t, kafka.ReasonValidationFailed, rejectErr.Reason)
+		require.Len(t, rejectErr.ValidationErrors(), 1)
+		assert.Equal(t, "payment.transaction", rejectErr.ValidationErrors()[0].Field)
+	})
+}
+
+func TestOrderMessageProcessor_RetryPolicy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	policy := kafka.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Multiplier:     2,
+	}
+
+	tests := []struct {
+		name           string
+		failError      error
+		expectedReason string
+		expectedCalls  int
+	}{
+		{
+			name:           "retryable_error_exhausts_attempts",
+			failError:      &pq.Error{Code: "40P01"}, // deadlock_detected
+			expectedReason: kafka.ReasonRetriesExhausted,
+			expectedCalls:  3,
+		},
+		{
+			name:           "connection_error_exhausts_attempts",
+			failError:      errors.New("dial tcp: connection refused"),
+			expectedReason: kafka.ReasonRetriesExhausted,
+			expectedCalls:  3,
+		},
+		{
+			name:           "permanent_error_not_retried",
+			failError:      fmt.Errorf("%w: %w", repository.ErrPermanent, &pq.Error{Code: "23502"}), // not_null_violation
+			expectedReason: kafka.ReasonPersistFailed,
+			expectedCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockRepository()
+			mockRepo.ShouldFail = true
+			mockRepo.FailError = tt.failError
+
+			processor := kafka.NewOrderMessageProcessor(logger, policy)
+			err := processor.ProcessMessage(context.Background(), mustMarshalOrder(testutils.MinimalOrderFixture("retry_order")), mockRepo)
+
+			var rejectErr *kafka.RejectError
+			require.True(t, errors.As(err, &rejectErr))
+			assert.Equal(t, tt.expectedReason, rejectErr.Reason)
+			assert.ErrorIs(t, err, tt.failError)
+			assert.Equal(t, tt.expectedCalls, mockRepo.CallsCreateOrder)
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := kafka.RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 800*time.Millisecond, policy.Backoff(4))
+	assert.Equal(t, time.Second, policy.Backoff(10), "backoff is capped by MaxBackoff")
+
+	assert.False(t, policy.Exhausted(4))
+	assert.True(t, policy.Exhausted(5))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := policy.Backoff(2)
+		assert.GreaterOrEqual(t, backoff, 100*time.Millisecond)
+		assert.LessOrEqual(t, backoff, 300*time.Millisecond)
+	}
+}
+
+func TestNewDeadLetterMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	processor := kafka.NewOrderMessageProcessor(logger, testutils.RetryPolicy())
+
+	order := testutils.MinimalOrderFixture("dlq_order")
+	order.Delivery.Email = "not-an-email"
+	value := mustMarshalOrder(order)
+
+	cause := processor.ProcessMessage(context.Background(), value, mocks.NewMockRepository())
+	require.Error(t, cause)
+
+	src := source.Message{
+		Topic:     "orders",
+		Partition: 3,
+		Offset:    42,
+		Key:       []byte("dlq_order"),
+		Value:     value,
+		Headers:   []source.Header{{Key: "trace-id", Value: []byte("abc")}},
+	}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	msg := kafka.NewDeadLetterMessage(src, cause, now)
+
+	assert.Equal(t, src.Key, msg.Key)
+	assert.Equal(t, src.Value, msg.Value)
+	assert.Empty(t, msg.Topic, "topic is set by the DLQ writer")
+
+	headers := make(map[string]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+
+	assert.Equal(t, "abc", headers["trace-id"])
+	assert.Equal(t, kafka.ReasonValidationFailed, headers[kafka.HeaderDLQReason])
+	assert.Equal(t, cause.Error(), headers[kafka.HeaderDLQError])
+	assert.Equal(t, "orders", headers[kafka.HeaderDLQSourceTopic])
+	assert.Equal(t, "3", headers[kafka.HeaderDLQSourcePartition])
+	assert.Equal(t, "42", headers[kafka.HeaderDLQSourceOffset])
+	assert.Equal(t, "2024-01-02T03:04:05Z", headers[kafka.HeaderDLQTimestamp])
+	assert.Contains(t, headers[kafka.HeaderDLQValidationErrors], `"field":"Email"`)
+}
+
 // Вспомогательные функции
 
 func mustMarshalOrder(order *dto.OrderDTO) []byte {
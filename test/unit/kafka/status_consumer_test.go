@@ -0,0 +1,133 @@
+package kafka_test
+
+import (
+	"L0/internal/kafka"
+	"L0/internal/kafka/dto"
+	"L0/internal/models"
+	"L0/internal/service"
+	"L0/test/mocks"
+	"L0/test/testutils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusSinkFunc позволяет передать функцию как kafka.StatusEventSink
+type statusSinkFunc func(ctx context.Context, event *dto.StatusEventDTO) error
+
+func (f statusSinkFunc) ApplyStatusEvent(ctx context.Context, event *dto.StatusEventDTO) error {
+	return f(ctx, event)
+}
+
+func statusEvent(eventID, orderUID, status string) []byte {
+	data, _ := json.Marshal(dto.StatusEventDTO{
+		EventID:    eventID,
+		OrderUID:   orderUID,
+		Status:     status,
+		Source:     "warehouse",
+		OccurredAt: "2024-03-01T12:00:00Z",
+	})
+	return data
+}
+
+func TestStatusEventProcessor_ProcessMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	processor := kafka.NewStatusEventProcessor(logger, testutils.RetryPolicy())
+
+	t.Run("valid_event", func(t *testing.T) {
+		mockService := mocks.NewMockOrderService()
+
+		err := processor.ProcessMessage(context.Background(), statusEvent("evt-1", "order", "paid"), mockService)
+		require.NoError(t, err)
+		require.Len(t, mockService.StatusEvents, 1)
+		assert.Equal(t, "evt-1", mockService.StatusEvents[0].EventID)
+	})
+
+	rejected := []struct {
+		name   string
+		data   []byte
+		reason string
+	}{
+		{"invalid_format", []byte(`{"event_id": `), kafka.ReasonInvalidFormat},
+		{"missing_event_id", statusEvent("", "order", "paid"), kafka.ReasonValidationFailed},
+		{"unknown_status", statusEvent("evt-1", "order", "lost"), kafka.ReasonValidationFailed},
+		{"invalid_occurred_at", []byte(`{"event_id":"e","order_uid":"o","status":"paid","occurred_at":"yesterday"}`), kafka.ReasonValidationFailed},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockOrderService()
+
+			err := processor.ProcessMessage(context.Background(), tt.data, mockService)
+
+			var rejectErr *kafka.RejectError
+			require.True(t, errors.As(err, &rejectErr))
+			assert.Equal(t, tt.reason, rejectErr.Reason)
+			assert.Empty(t, mockService.StatusEvents)
+		})
+	}
+
+	t.Run("illegal_transition_is_not_retried", func(t *testing.T) {
+		calls := 0
+		sink := statusSinkFunc(func(ctx context.Context, event *dto.StatusEventDTO) error {
+			calls++
+			return fmt.Errorf("failed to apply status event: %w", models.ErrIllegalTransition)
+		})
+
+		err := processor.ProcessMessage(context.Background(), statusEvent("evt-1", "order", "delivered"), sink)
+
+		var rejectErr *kafka.RejectError
+		require.True(t, errors.As(err, &rejectErr))
+		assert.Equal(t, kafka.ReasonIllegalTransition, rejectErr.Reason)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("transient_error_is_retried", func(t *testing.T) {
+		policy := kafka.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 1}
+		processor := kafka.NewStatusEventProcessor(logger, policy)
+
+		calls := 0
+		sink := statusSinkFunc(func(ctx context.Context, event *dto.StatusEventDTO) error {
+			calls++
+			if calls < 3 {
+				return errors.New("connection reset")
+			}
+			return nil
+		})
+
+		require.NoError(t, processor.ProcessMessage(context.Background(), statusEvent("evt-1", "order", "paid"), sink))
+		assert.Equal(t, 3, calls)
+	})
+}
+
+func TestStatusEventProcessor_ThroughOrderService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	mockRepo := mocks.NewMockRepository()
+	orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger)
+	statusProcessor := kafka.NewStatusEventProcessor(logger, testutils.RetryPolicy())
+	orderProcessor := kafka.NewOrderMessageProcessor(logger, testutils.RetryPolicy())
+
+	// Событие пришло раньше заказа
+	require.NoError(t, statusProcessor.ProcessMessage(context.Background(), statusEvent("evt-1", "late_order", "paid"), orderService))
+	assert.Equal(t, 1, mockRepo.PendingCount())
+
+	order := testutils.MinimalOrderFixture("late_order")
+	order.Status = string(models.StatusCreated)
+	require.NoError(t, orderProcessor.ProcessMessage(context.Background(), mustMarshalOrder(order), orderService))
+
+	stored, err := orderService.GetOrder(context.Background(), "late_order")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.StatusPaid), stored.Status)
+	assert.Zero(t, mockRepo.PendingCount())
+
+	// Повторная доставка того же события ничего не меняет
+	require.NoError(t, statusProcessor.ProcessMessage(context.Background(), statusEvent("evt-1", "late_order", "paid"), orderService))
+	assert.Len(t, mockRepo.StatusChanges, 1)
+}
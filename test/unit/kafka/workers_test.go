@@ -0,0 +1,178 @@
+package kafka_test
+
+import (
+	"L0/internal/kafka"
+	"L0/internal/kafka/dto"
+	"L0/internal/source"
+	"L0/test/testutils"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReader - source.Reader, который отдаёт заранее заданные сообщения и запоминает коммиты
+type fakeReader struct {
+	mu       sync.Mutex
+	messages []source.Message
+	next     int
+	commits  []source.Message
+}
+
+func newFakeReader(partitions, perPartition int) *fakeReader {
+	r := &fakeReader{}
+	// Партиции перемешаны, как при чтении из нескольких партиций сразу
+	for offset := 0; offset < perPartition; offset++ {
+		for partition := 0; partition < partitions; partition++ {
+			uid := fmt.Sprintf("p%d_%03d", partition, offset)
+			r.messages = append(r.messages, source.Message{
+				Topic:     "orders",
+				Partition: partition,
+				Offset:    int64(offset),
+				Value:     mustMarshalOrder(testutils.MinimalOrderFixture(uid)),
+			})
+		}
+	}
+	return r
+}
+
+func (r *fakeReader) Fetch(ctx context.Context) (source.Message, error) {
+	r.mu.Lock()
+	if r.next < len(r.messages) {
+		m := r.messages[r.next]
+		r.next++
+		r.mu.Unlock()
+		return m, nil
+	}
+	r.mu.Unlock()
+
+	<-ctx.Done()
+	return source.Message{}, ctx.Err()
+}
+
+func (r *fakeReader) Commit(_ context.Context, msgs ...source.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commits = append(r.commits, msgs...)
+	return nil
+}
+
+func (r *fakeReader) Close() error { return nil }
+
+// committed возвращает последнее закоммиченное смещение по партициям и проверяет, что коммиты не откатываются
+func (r *fakeReader) committed(t *testing.T) map[int]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	latest := make(map[int]int64)
+	for _, m := range r.commits {
+		if prev, ok := latest[m.Partition]; ok {
+			assert.Greater(t, m.Offset, prev, "partition %d commit went backwards", m.Partition)
+		}
+		latest[m.Partition] = m.Offset
+	}
+	return latest
+}
+
+// orderSinkFunc позволяет передать функцию как kafka.OrderSink
+type orderSinkFunc func(ctx context.Context, order *dto.OrderDTO) (*dto.OrderDTO, error)
+
+func (f orderSinkFunc) CreateOrder(ctx context.Context, order *dto.OrderDTO) (*dto.OrderDTO, error) {
+	return f(ctx, order)
+}
+
+func TestOrderConsumer_Workers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	newConsumer := func(workers int) kafka.Consumer {
+		return kafka.NewOrderConsumer(logger, nil, testutils.RetryPolicy(), kafka.ConsumerOptions{Workers: workers})
+	}
+
+	t.Run("processes_partitions_in_parallel_keeping_order", func(t *testing.T) {
+		const partitions, perPartition = 4, 20
+		reader := newFakeReader(partitions, perPartition)
+
+		var (
+			mu        sync.Mutex
+			processed = make(map[string][]string)
+			inFlight  atomic.Int32
+			maxFlight atomic.Int32
+			total     atomic.Int32
+		)
+		sink := orderSinkFunc(func(_ context.Context, order *dto.OrderDTO) (*dto.OrderDTO, error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				m := maxFlight.Load()
+				if n <= m || maxFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			partition := order.OrderUID[:2]
+			processed[partition] = append(processed[partition], order.OrderUID)
+			mu.Unlock()
+			total.Add(1)
+			return order, nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- newConsumer(partitions).ConsumeOrders(ctx, reader, sink) }()
+
+		require.Eventually(t, func() bool { return total.Load() == partitions*perPartition }, 5*time.Second, 5*time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+
+		assert.Greater(t, maxFlight.Load(), int32(1), "partitions were not processed concurrently")
+		for partition := 0; partition < partitions; partition++ {
+			key := fmt.Sprintf("p%d", partition)
+			want := make([]string, 0, perPartition)
+			for offset := 0; offset < perPartition; offset++ {
+				want = append(want, fmt.Sprintf("p%d_%03d", partition, offset))
+			}
+			assert.Equal(t, want, processed[key])
+		}
+
+		committed := reader.committed(t)
+		for partition := 0; partition < partitions; partition++ {
+			assert.Equal(t, int64(perPartition-1), committed[partition])
+		}
+	})
+
+	t.Run("does_not_commit_unfinished_messages_on_shutdown", func(t *testing.T) {
+		reader := newFakeReader(2, 5)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		release := make(chan struct{})
+		sink := orderSinkFunc(func(ctx context.Context, order *dto.OrderDTO) (*dto.OrderDTO, error) {
+			// Партиция 1 застревает на сообщении со смещением 2 до остановки
+			if order.OrderUID == "p1_002" {
+				close(release)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return order, nil
+		})
+
+		done := make(chan error, 1)
+		go func() { done <- newConsumer(2).ConsumeOrders(ctx, reader, sink) }()
+
+		<-release
+		require.Eventually(t, func() bool { return reader.committed(t)[0] == 4 }, 5*time.Second, 5*time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+
+		committed := reader.committed(t)
+		assert.Equal(t, int64(4), committed[0])
+		assert.Equal(t, int64(1), committed[1], "messages after the interrupted one must not be committed")
+	})
+}
@@ -0,0 +1,71 @@
+package metrics_test
+
+import (
+	"L0/internal/cache"
+	"L0/internal/metrics"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(metrics.HTTPMiddleware)
+	r.Get("/orders/{order_uid}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "order_uid") == "missing" {
+			http.Error(w, "order not found", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	})
+
+	for _, url := range []string{"/orders/a", "/orders/b", "/orders/missing", "/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.HTTPRequestsTotal.WithLabelValues("GET", "/orders/{order_uid}", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.HTTPRequestsTotal.WithLabelValues("GET", "/orders/{order_uid}", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.HTTPRequestsTotal.WithLabelValues("GET", "unmatched", "404")))
+	assert.Equal(t, 3, testutil.CollectAndCount(metrics.HTTPRequestsTotal))
+}
+
+// countingStats считает обращения к статистике кэша
+type countingStats struct {
+	*cache.GoCache[string]
+	calls atomic.Int32
+}
+
+func (c *countingStats) Stats() cache.Stats {
+	c.calls.Add(1)
+	return c.GoCache.Stats()
+}
+
+func TestHandler_ExposesCacheStats(t *testing.T) {
+	c := cache.New[string](5*time.Minute, 10*time.Minute)
+	provider := &countingStats{GoCache: c}
+	metrics.RegisterCache(provider)
+
+	c.Set("key", "value", 0)
+	c.Get("key")
+	c.Get("missing")
+	c.Delete("key")
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	assert.Contains(t, body, "l0_cache_hits_total 1")
+	assert.Contains(t, body, "l0_cache_misses_total 1")
+	assert.Contains(t, body, "l0_cache_evictions_total 1")
+	assert.Contains(t, body, "l0_cache_entries 0")
+	assert.Contains(t, body, "go_goroutines")
+	assert.Equal(t, int32(1), provider.calls.Load(), "stats must be read once per scrape")
+}
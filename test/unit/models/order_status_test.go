@@ -0,0 +1,53 @@
+package models_test
+
+import (
+	"L0/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOrderStatus(t *testing.T) {
+	status, err := models.ParseOrderStatus("shipped")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusShipped, status)
+
+	_, err = models.ParseOrderStatus("lost")
+	assert.ErrorIs(t, err, models.ErrUnknownStatus)
+	_, err = models.ParseOrderStatus("")
+	assert.ErrorIs(t, err, models.ErrUnknownStatus)
+}
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		from, to models.OrderStatus
+		allowed  bool
+	}{
+		{models.StatusCreated, models.StatusPaid, true},
+		{models.StatusPaid, models.StatusAssembling, true},
+		{models.StatusAssembling, models.StatusShipped, true},
+		{models.StatusShipped, models.StatusDelivered, true},
+		{models.StatusDelivered, models.StatusReturned, true},
+		{models.StatusCreated, models.StatusCancelled, true},
+		{models.StatusAssembling, models.StatusCancelled, true},
+
+		{models.StatusCreated, models.StatusShipped, false},
+		{models.StatusShipped, models.StatusCancelled, false},
+		{models.StatusDelivered, models.StatusShipped, false},
+		{models.StatusCancelled, models.StatusPaid, false},
+		{models.StatusReturned, models.StatusDelivered, false},
+		{models.StatusPaid, models.StatusPaid, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"_to_"+string(tt.to), func(t *testing.T) {
+			err := models.ValidateTransition(tt.from, tt.to)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, models.ErrIllegalTransition)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package repository_test
+
+import (
+	"L0/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderFilter_PageSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter repository.OrderFilter
+		want   int
+	}{
+		{"default", repository.OrderFilter{}, repository.DefaultPageSize},
+		{"within_limit", repository.OrderFilter{Limit: 50}, 50},
+		{"capped", repository.OrderFilter{Limit: 1_000_000}, repository.MaxPageSize},
+		{"batch_above_page_limit", repository.OrderFilter{Limit: 500, Batch: true}, 500},
+		{"batch_capped", repository.OrderFilter{Limit: 1_000_000, Batch: true}, repository.MaxBatchSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.PageSize())
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"L0/test/testutils"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Бенчмарки сравнивают способы отдать заказ из кэша в HTTP-ответ:
+// общий указатель (без защиты от изменения), глубокую копию и заранее закодированный JSON.
+
+func BenchmarkCachedOrder_SharedPointer(b *testing.B) {
+	c := cache.New[*dto.OrderDTO](5*time.Minute, 10*time.Minute)
+	c.Set("order", testutils.OrderFixture(), cache.NoExpiration)
+	recorder := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		recorder.Body.Reset()
+		order, _ := c.Get("order")
+		if err := json.NewEncoder(recorder).Encode(order); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCachedOrder_DeepCopy(b *testing.B) {
+	c := cache.New[*dto.OrderDTO](5*time.Minute, 10*time.Minute)
+	c.Set("order", testutils.OrderFixture(), cache.NoExpiration)
+	recorder := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		recorder.Body.Reset()
+		order, _ := c.Get("order")
+		if err := json.NewEncoder(recorder).Encode(order.Clone()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCachedOrder_PreEncodedJSON(b *testing.B) {
+	c := cache.New[[]byte](5*time.Minute, 10*time.Minute)
+	data, err := json.Marshal(testutils.OrderFixture())
+	if err != nil {
+		b.Fatal(err)
+	}
+	c.Set("order", append(data, '\n'), cache.NoExpiration)
+	recorder := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		recorder.Body.Reset()
+		body, _ := c.Get("order")
+		if _, err := recorder.Write(body); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOrderDTO_Clone(b *testing.B) {
+	order := testutils.OrderFixture()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = order.Clone()
+	}
+}
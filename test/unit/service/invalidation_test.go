@@ -0,0 +1,119 @@
+package service_test
+
+import (
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"L0/internal/repository/postgres"
+	"L0/internal/service"
+	"L0/test/mocks"
+	"L0/test/testutils"
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheInvalidator(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	repo := mocks.NewMockRepository()
+	_, err := repo.CreateOrder(context.Background(), testutils.MinimalOrderFixture("in_db"))
+	require.NoError(t, err)
+
+	cache := mocks.NewMockCache()
+	cache.Store["changed"] = testutils.MinimalOrderFixture("changed")
+	cache.Store["untouched"] = testutils.MinimalOrderFixture("untouched")
+
+	orderService := service.NewOrderService(repo, cache, logger, service.WithNegativeCache(time.Minute, 100))
+	warmer := service.NewCacheWarmer(repo, cache, logger, service.WarmupOptions{})
+	admin := service.NewCacheAdmin(context.Background(), cache, warmer, logger)
+	invalidator := service.NewCacheInvalidator(cache, admin, orderService, logger)
+
+	t.Run("order_changed_evicts_key", func(t *testing.T) {
+		invalidator.OrderChanged(postgres.OrderChange{OrderUID: "changed", Op: postgres.OpCreated, Origin: "other"})
+
+		assert.NotContains(t, cache.Store, "changed")
+		assert.Contains(t, cache.Store, "untouched")
+	})
+
+	t.Run("order_created_elsewhere_is_no_longer_missing", func(t *testing.T) {
+		_, err := orderService.GetOrder(context.Background(), "created_elsewhere")
+		require.Error(t, err)
+
+		// Заказ сохранён другой репликой: эта реплика узнаёт о нём только из уведомления
+		_, err = repo.CreateOrder(context.Background(), testutils.MinimalOrderFixture("created_elsewhere"))
+		require.NoError(t, err)
+		_, err = orderService.GetOrder(context.Background(), "created_elsewhere")
+		require.Error(t, err, "missing order is cached")
+
+		invalidator.OrderChanged(postgres.OrderChange{OrderUID: "created_elsewhere", Op: postgres.OpCreated, Origin: "other"})
+
+		order, err := orderService.GetOrder(context.Background(), "created_elsewhere")
+		require.NoError(t, err)
+		assert.Equal(t, "created_elsewhere", order.OrderUID)
+	})
+
+	t.Run("resync_flushes_and_rewarms", func(t *testing.T) {
+		_, err := orderService.GetOrder(context.Background(), "lost_notification")
+		require.Error(t, err)
+		_, err = repo.CreateOrder(context.Background(), testutils.MinimalOrderFixture("lost_notification"))
+		require.NoError(t, err)
+
+		invalidator.Resync()
+
+		require.Eventually(t, func() bool {
+			status := admin.Stats().Rewarm
+			return !status.Running && status.FinishedAt != nil
+		}, time.Second, 5*time.Millisecond)
+
+		assert.ElementsMatch(t, []string{"in_db", "created_elsewhere", "lost_notification"}, cache.Keys())
+
+		_, err = orderService.GetOrder(context.Background(), "lost_notification")
+		assert.NoError(t, err, "resync must clear the negative cache")
+	})
+}
+
+func TestCacheInvalidator_KeepsSharedCache(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	server := miniredis.RunT(t)
+	l1 := cache.New[*dto.OrderDTO](time.Minute, time.Minute)
+	shared := cache.NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}), cache.RedisOptions[*dto.OrderDTO]{
+		Prefix: "orders:",
+		L1:     l1,
+	})
+	t.Cleanup(func() { _ = shared.Close() })
+	shared.Set("cached", testutils.MinimalOrderFixture("cached"), cache.DefaultExpiration)
+	require.Equal(t, 1, l1.Len())
+
+	repo := mocks.NewMockRepository()
+	orderService := service.NewOrderService(repo, shared, logger)
+	warmer := service.NewCacheWarmer(repo, shared, logger, service.WarmupOptions{})
+	admin := service.NewCacheAdmin(context.Background(), shared, warmer, logger)
+
+	invalidator := service.NewCacheInvalidator(shared, admin, orderService, logger)
+
+	t.Run("order_changed_keeps_shared_entry", func(t *testing.T) {
+		invalidator.OrderChanged(postgres.OrderChange{OrderUID: "cached", Op: postgres.OpCreated, Origin: "other"})
+
+		assert.Zero(t, l1.Len(), "local copy must be dropped")
+		assert.True(t, server.Exists("orders:cached"), "entry written by the origin replica must survive")
+	})
+
+	t.Run("resync_flushes_only_local_tier", func(t *testing.T) {
+		shared.Set("cached", testutils.MinimalOrderFixture("cached"), cache.DefaultExpiration)
+		require.Equal(t, 1, l1.Len())
+
+		invalidator.Resync()
+
+		assert.Zero(t, l1.Len(), "local tier must be flushed")
+		assert.True(t, server.Exists("orders:cached"), "shared Redis must not be flushed by one replica")
+		assert.Nil(t, admin.Stats().Rewarm.StartedAt, "shared cache needs no rewarm")
+	})
+}
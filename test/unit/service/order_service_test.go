@@ -1,7 +1,9 @@
 package service_test
 
 import (
+	"L0/internal/cache"
 	"L0/internal/kafka/dto"
+	"L0/internal/models"
 	"L0/internal/service"
 	"L0/test/mocks"
 	"L0/test/testutils"
@@ -9,11 +11,13 @@ import (
 	"errors"
 	"log/slog"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestOrderService_GetOrder(t *testing.T) {
@@ -86,25 +90,6 @@ func TestOrderService_GetOrder(t *testing.T) {
 				"cache_set": 0,
 			},
 		},
-		{
-			name:     "invalid_cache_data",
-			orderUID: "test_order_4",
-			setupMocks: func(repo *mocks.MockRepository, cache *mocks.MockCache) {
-				cache.Store["test_order_4"] = "invalid_data"
-				order := testutils.MinimalOrderFixture("test_order_4")
-				_, err := repo.CreateOrder(context.Background(), order)
-				if err != nil {
-					require.NoError(t, err, "Failed to create test order in mock repository")
-				}
-			},
-			expectedError: "",
-			expectedCalls: map[string]int{
-				"cache_get":    1,
-				"cache_delete": 1,
-				"repo_get":     1,
-				"cache_set":    1,
-			},
-		},
 	}
 
 	for _, tt := range tests {
@@ -199,6 +184,46 @@ func TestOrderService_CreateOrder(t *testing.T) {
 	}
 }
 
+func TestOrderService_CreateOrders(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	t.Run("caches_all_orders", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		mockCache := mocks.NewMockCache()
+		orderService := service.NewOrderService(mockRepo, mockCache, logger)
+
+		orders := []*dto.OrderDTO{
+			testutils.MinimalOrderFixture("batch_1"),
+			testutils.MinimalOrderFixture("batch_2"),
+		}
+		created, err := orderService.CreateOrders(context.Background(), orders)
+
+		require.NoError(t, err)
+		assert.Len(t, created, 2)
+		assert.Equal(t, 1, mockRepo.CallsCreateOrders)
+		assert.Contains(t, mockCache.Store, "batch_1")
+		assert.Contains(t, mockCache.Store, "batch_2")
+	})
+
+	t.Run("nothing_cached_when_batch_fails", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		mockCache := mocks.NewMockCache()
+		orderService := service.NewOrderService(mockRepo, mockCache, logger)
+
+		_, err := mockRepo.CreateOrder(context.Background(), testutils.MinimalOrderFixture("batch_2"))
+		require.NoError(t, err)
+
+		_, err = orderService.CreateOrders(context.Background(), []*dto.OrderDTO{
+			testutils.MinimalOrderFixture("batch_1"),
+			testutils.MinimalOrderFixture("batch_2"),
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create orders")
+		assert.Zero(t, mockCache.CallsSet)
+	})
+}
+
 func TestOrderService_GetOrder_ConcurrentAccess(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 
@@ -242,3 +267,406 @@ func TestOrderService_GetOrder_ConcurrentAccess(t *testing.T) {
 
 	assert.Greater(t, mockCache.CallsSet, 0, "Cache should be set at least once")
 }
+
+func TestOrderService_GetOrder_CoalescesConcurrentMisses(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.GetGate = make(chan struct{})
+	_, err := mockRepo.CreateOrder(context.Background(), testutils.MinimalOrderFixture("hot_order"))
+	require.NoError(t, err)
+
+	orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger)
+
+	const numGoroutines = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, numGoroutines)
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := orderService.GetOrder(context.Background(), "hot_order")
+			errs <- err
+		}()
+	}
+
+	// Ждём, пока первый запрос дойдёт до репозитория, и даём остальным встать в очередь
+	require.Eventually(t, func() bool { return mockRepo.GetOrderByUIDCalls() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(mockRepo.GetGate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, mockRepo.GetOrderByUIDCalls())
+}
+
+func TestOrderService_GetOrder_CallerCancellation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	mockRepo := mocks.NewMockRepository()
+	mockRepo.GetGate = make(chan struct{})
+	_, err := mockRepo.CreateOrder(context.Background(), testutils.MinimalOrderFixture("slow_order"))
+	require.NoError(t, err)
+
+	orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := orderService.GetOrder(ctx, "slow_order")
+		cancelled <- err
+	}()
+	require.Eventually(t, func() bool { return mockRepo.GetOrderByUIDCalls() == 1 }, time.Second, time.Millisecond)
+
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := orderService.GetOrder(context.Background(), "slow_order")
+		waiting <- err
+	}()
+
+	// Отмена первого вызова не прерывает общий запрос к БД
+	cancel()
+	assert.ErrorIs(t, <-cancelled, context.Canceled)
+
+	close(mockRepo.GetGate)
+	assert.NoError(t, <-waiting)
+	assert.Equal(t, 1, mockRepo.GetOrderByUIDCalls())
+}
+
+func TestOrderService_NegativeCache(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	t.Run("missing_order_is_cached", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger,
+			service.WithNegativeCache(time.Minute, 100))
+
+		for i := 0; i < 3; i++ {
+			_, err := orderService.GetOrder(context.Background(), "missing")
+			require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		}
+		assert.Equal(t, 1, mockRepo.CallsGetOrderByUID)
+	})
+
+	t.Run("entry_expires", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger,
+			service.WithNegativeCache(20*time.Millisecond, 100))
+
+		_, err := orderService.GetOrder(context.Background(), "missing")
+		require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		time.Sleep(30 * time.Millisecond)
+		_, err = orderService.GetOrder(context.Background(), "missing")
+		require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		assert.Equal(t, 2, mockRepo.CallsGetOrderByUID)
+	})
+
+	t.Run("invalidated_on_create", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger,
+			service.WithNegativeCache(time.Minute, 100))
+
+		_, err := orderService.GetOrder(context.Background(), "late_order")
+		require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		_, err = orderService.CreateOrder(context.Background(), testutils.MinimalOrderFixture("late_order"))
+		require.NoError(t, err)
+
+		order, err := orderService.GetOrder(context.Background(), "late_order")
+		require.NoError(t, err)
+		assert.Equal(t, "late_order", order.OrderUID)
+	})
+
+	t.Run("errors_are_not_cached", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		mockRepo.ShouldFail = true
+		mockRepo.FailError = errors.New("database error")
+		orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger,
+			service.WithNegativeCache(time.Minute, 100))
+
+		for i := 0; i < 2; i++ {
+			_, err := orderService.GetOrder(context.Background(), "any")
+			require.Error(t, err)
+		}
+		assert.Equal(t, 2, mockRepo.CallsGetOrderByUID)
+	})
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger)
+
+		for i := 0; i < 2; i++ {
+			_, err := orderService.GetOrder(context.Background(), "missing")
+			require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		}
+		assert.Equal(t, 2, mockRepo.CallsGetOrderByUID)
+	})
+}
+
+func TestOrderService_ReturnsIndependentCopies(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	mockRepo := mocks.NewMockRepository()
+	mockCache := mocks.NewMockCache()
+	orderService := service.NewOrderService(mockRepo, mockCache, logger)
+
+	created, err := orderService.CreateOrder(context.Background(), testutils.OrderFixture())
+	require.NoError(t, err)
+	uid := created.OrderUID
+
+	// Изменение результата CreateOrder не попадает в кэш
+	created.Items[0].Name = "changed by producer"
+
+	first, err := orderService.GetOrder(context.Background(), uid)
+	require.NoError(t, err)
+	assert.NotEqual(t, "changed by producer", first.Items[0].Name)
+
+	// Изменение результата GetOrder не видно следующему вызову
+	first.CustomerID = "changed by caller"
+	first.Items[0].Price = -1
+
+	second, err := orderService.GetOrder(context.Background(), uid)
+	require.NoError(t, err)
+	assert.NotEqual(t, "changed by caller", second.CustomerID)
+	assert.NotEqual(t, -1, second.Items[0].Price)
+	assert.NotSame(t, mockCache.Store[uid], second)
+}
+
+func TestOrderService_RefreshAhead(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	newCache := func() *cache.LRU[string, *dto.OrderDTO] {
+		return cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{DefaultTTL: time.Hour})
+	}
+
+	t.Run("serves_stale_and_refreshes", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		fresh := testutils.MinimalOrderFixture("order")
+		fresh.TrackNumber = "fresh"
+		_, err := mockRepo.CreateOrder(context.Background(), fresh)
+		require.NoError(t, err)
+
+		c := newCache()
+		stale := testutils.MinimalOrderFixture("order")
+		stale.TrackNumber = "stale"
+		c.Set("order", stale, 30*time.Second)
+
+		orderService := service.NewOrderService(mockRepo, c, logger, service.WithRefreshAhead(time.Minute))
+
+		order, err := orderService.GetOrder(context.Background(), "order")
+		require.NoError(t, err)
+		assert.Equal(t, "stale", order.TrackNumber)
+
+		require.Eventually(t, func() bool {
+			cached, found := c.Get("order")
+			return found && cached.TrackNumber == "fresh"
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, 1, mockRepo.GetOrderByUIDCalls())
+
+		// Обновлённая запись получила полный TTL и больше не обновляется
+		_, err = orderService.GetOrder(context.Background(), "order")
+		require.NoError(t, err)
+		assert.Equal(t, 1, mockRepo.GetOrderByUIDCalls())
+	})
+
+	t.Run("fresh_entry_is_not_refreshed", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		c := newCache()
+		c.Set("order", testutils.MinimalOrderFixture("order"), cache.DefaultExpiration)
+		orderService := service.NewOrderService(mockRepo, c, logger, service.WithRefreshAhead(time.Minute))
+
+		_, err := orderService.GetOrder(context.Background(), "order")
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+		assert.Zero(t, mockRepo.GetOrderByUIDCalls())
+	})
+
+	t.Run("deleted_order_is_evicted", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		c := newCache()
+		c.Set("gone", testutils.MinimalOrderFixture("gone"), 30*time.Second)
+		orderService := service.NewOrderService(mockRepo, c, logger, service.WithRefreshAhead(time.Minute))
+
+		_, err := orderService.GetOrder(context.Background(), "gone")
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			_, found := c.Get("gone")
+			return !found
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("cache_without_expiration_info", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		mockCache := mocks.NewMockCache()
+		mockCache.Set("order", testutils.MinimalOrderFixture("order"), cache.DefaultExpiration)
+		orderService := service.NewOrderService(mockRepo, mockCache, logger, service.WithRefreshAhead(time.Minute))
+
+		_, err := orderService.GetOrder(context.Background(), "order")
+		require.NoError(t, err)
+		assert.Zero(t, mockRepo.GetOrderByUIDCalls())
+	})
+}
+
+func TestOrderService_UpdateOrderStatus(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	setup := func(t *testing.T) (*mocks.MockRepository, *mocks.MockCache, service.OrderService) {
+		mockRepo := mocks.NewMockRepository()
+		order := testutils.MinimalOrderFixture("status_order")
+		order.Status = string(models.StatusCreated)
+		_, err := mockRepo.CreateOrder(context.Background(), order)
+		require.NoError(t, err)
+
+		mockCache := mocks.NewMockCache()
+		return mockRepo, mockCache, service.NewOrderService(mockRepo, mockCache, logger)
+	}
+
+	t.Run("legal_transition_updates_cache", func(t *testing.T) {
+		mockRepo, mockCache, orderService := setup(t)
+		// В кэше устаревшая версия заказа
+		stale := testutils.MinimalOrderFixture("status_order")
+		stale.Status = string(models.StatusCreated)
+		mockCache.Set("status_order", stale, cache.DefaultExpiration)
+
+		order, err := orderService.UpdateOrderStatus(context.Background(), "status_order", models.StatusPaid, "payment received")
+		require.NoError(t, err)
+		assert.Equal(t, string(models.StatusPaid), order.Status)
+
+		require.Len(t, mockRepo.StatusChanges, 1)
+		change := mockRepo.StatusChanges[0]
+		assert.Equal(t, models.StatusCreated, change.From)
+		assert.Equal(t, models.StatusPaid, change.To)
+		assert.Equal(t, "payment received", change.Reason)
+
+		cached, err := orderService.GetOrder(context.Background(), "status_order")
+		require.NoError(t, err)
+		assert.Equal(t, string(models.StatusPaid), cached.Status)
+	})
+
+	t.Run("illegal_transition", func(t *testing.T) {
+		mockRepo, mockCache, orderService := setup(t)
+
+		_, err := orderService.UpdateOrderStatus(context.Background(), "status_order", models.StatusDelivered, "")
+		require.ErrorIs(t, err, models.ErrIllegalTransition)
+		assert.Empty(t, mockRepo.StatusChanges)
+		assert.Zero(t, mockCache.Len())
+	})
+
+	t.Run("transition_checked_against_database", func(t *testing.T) {
+		_, mockCache, orderService := setup(t)
+		_, err := orderService.UpdateOrderStatus(context.Background(), "status_order", models.StatusCancelled, "customer request")
+		require.NoError(t, err)
+
+		// Даже если кэш отстал, отменённый заказ нельзя оплатить
+		stale := testutils.MinimalOrderFixture("status_order")
+		stale.Status = string(models.StatusCreated)
+		mockCache.Set("status_order", stale, cache.DefaultExpiration)
+
+		_, err = orderService.UpdateOrderStatus(context.Background(), "status_order", models.StatusPaid, "")
+		require.ErrorIs(t, err, models.ErrIllegalTransition)
+	})
+
+	t.Run("order_not_found", func(t *testing.T) {
+		_, _, orderService := setup(t)
+
+		_, err := orderService.UpdateOrderStatus(context.Background(), "missing", models.StatusPaid, "")
+		require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestOrderService_ApplyStatusEvent(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	event := func(eventID, orderUID, status, occurredAt string) *dto.StatusEventDTO {
+		return &dto.StatusEventDTO{EventID: eventID, OrderUID: orderUID, Status: status, OccurredAt: occurredAt}
+	}
+	createOrder := func(t *testing.T, orderService service.OrderService, orderUID string) {
+		order := testutils.MinimalOrderFixture(orderUID)
+		order.Status = string(models.StatusCreated)
+		_, err := orderService.CreateOrder(context.Background(), order)
+		require.NoError(t, err)
+	}
+
+	t.Run("applied_once", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger)
+		createOrder(t, orderService, "order")
+
+		for i := 0; i < 2; i++ {
+			require.NoError(t, orderService.ApplyStatusEvent(context.Background(), event("evt-1", "order", "paid", "2024-03-01T12:00:00Z")))
+		}
+
+		require.Len(t, mockRepo.StatusChanges, 1)
+		assert.Equal(t, "evt-1", mockRepo.StatusChanges[0].EventID)
+		assert.Equal(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), mockRepo.StatusChanges[0].ChangedAt.UTC())
+
+		order, err := orderService.GetOrder(context.Background(), "order")
+		require.NoError(t, err)
+		assert.Equal(t, string(models.StatusPaid), order.Status)
+	})
+
+	t.Run("same_status_is_skipped", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger)
+		createOrder(t, orderService, "order")
+
+		require.NoError(t, orderService.ApplyStatusEvent(context.Background(), event("evt-1", "order", "created", "2024-03-01T12:00:00Z")))
+		assert.Empty(t, mockRepo.StatusChanges)
+
+		// Повтор пропущенного события после следующей смены статуса - дубликат, а не недопустимый переход
+		require.NoError(t, orderService.ApplyStatusEvent(context.Background(), event("evt-2", "order", "paid", "2024-03-01T13:00:00Z")))
+		require.NoError(t, orderService.ApplyStatusEvent(context.Background(), event("evt-1", "order", "created", "2024-03-01T12:00:00Z")))
+		require.Len(t, mockRepo.StatusChanges, 1)
+		assert.Equal(t, "evt-2", mockRepo.StatusChanges[0].EventID)
+	})
+
+	t.Run("illegal_transition", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger)
+		createOrder(t, orderService, "order")
+
+		err := orderService.ApplyStatusEvent(context.Background(), event("evt-1", "order", "delivered", "2024-03-01T12:00:00Z"))
+		require.ErrorIs(t, err, models.ErrIllegalTransition)
+		assert.Empty(t, mockRepo.StatusChanges)
+	})
+
+	t.Run("deferred_until_order_arrives", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger)
+
+		// События пришли не по порядку и раньше заказа
+		require.NoError(t, orderService.ApplyStatusEvent(context.Background(), event("evt-2", "order", "assembling", "2024-03-01T13:00:00Z")))
+		require.NoError(t, orderService.ApplyStatusEvent(context.Background(), event("evt-1", "order", "paid", "2024-03-01T12:00:00Z")))
+		require.Equal(t, 2, mockRepo.PendingCount())
+
+		createOrder(t, orderService, "order")
+
+		assert.Zero(t, mockRepo.PendingCount())
+		require.Len(t, mockRepo.StatusChanges, 2)
+		assert.Equal(t, "evt-1", mockRepo.StatusChanges[0].EventID)
+		assert.Equal(t, "evt-2", mockRepo.StatusChanges[1].EventID)
+
+		order, err := orderService.GetOrder(context.Background(), "order")
+		require.NoError(t, err)
+		assert.Equal(t, string(models.StatusAssembling), order.Status)
+	})
+
+	t.Run("illegal_pending_event_is_dropped", func(t *testing.T) {
+		mockRepo := mocks.NewMockRepository()
+		orderService := service.NewOrderService(mockRepo, mocks.NewMockCache(), logger)
+
+		require.NoError(t, orderService.ApplyStatusEvent(context.Background(), event("evt-1", "order", "delivered", "2024-03-01T12:00:00Z")))
+		require.NoError(t, orderService.ApplyStatusEvent(context.Background(), event("evt-2", "order", "cancelled", "2024-03-01T13:00:00Z")))
+
+		createOrder(t, orderService, "order")
+
+		assert.Zero(t, mockRepo.PendingCount())
+		require.Len(t, mockRepo.StatusChanges, 1)
+		assert.Equal(t, models.StatusCancelled, mockRepo.StatusChanges[0].To)
+	})
+}
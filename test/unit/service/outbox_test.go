@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"L0/internal/metrics"
+	"L0/internal/repository"
+	"L0/internal/service"
+	"L0/test/mocks"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher запоминает опубликованные пакеты
+type recordingPublisher struct {
+	mu      sync.Mutex
+	batches [][]repository.OutboxEvent
+	err     error
+}
+
+func (p *recordingPublisher) PublishEvents(_ context.Context, events []repository.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.batches = append(p.batches, events)
+	return nil
+}
+
+func (p *recordingPublisher) published() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var ids []string
+	for _, batch := range p.batches {
+		for _, event := range batch {
+			ids = append(ids, event.AggregateID)
+		}
+	}
+	return ids
+}
+
+// blockingPublisher ждёт отмены контекста, как публикация в недоступный брокер
+type blockingPublisher struct{}
+
+func (blockingPublisher) PublishEvents(ctx context.Context, _ []repository.OutboxEvent) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestOutboxRelay(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	newOutbox := func(n int) *mocks.MockOutbox {
+		outbox := mocks.NewMockOutbox()
+		for i := 0; i < n; i++ {
+			outbox.Add(fmt.Sprintf("order_%02d", i), time.Now())
+		}
+		return outbox
+	}
+
+	t.Run("publishes_in_batches_preserving_order", func(t *testing.T) {
+		outbox := newOutbox(5)
+		publisher := &recordingPublisher{}
+		relay := service.NewOutboxRelay(outbox, publisher, logger, service.OutboxOptions{BatchSize: 2})
+		before := testutil.ToFloat64(metrics.OutboxEventsPublished)
+
+		for _, want := range []int{2, 2, 1, 0} {
+			n, err := relay.PublishBatch(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, want, n)
+		}
+
+		assert.Len(t, publisher.batches, 3)
+		assert.Equal(t, []string{"order_00", "order_01", "order_02", "order_03", "order_04"}, publisher.published())
+		assert.Zero(t, outbox.Pending())
+		assert.Equal(t, 5.0, testutil.ToFloat64(metrics.OutboxEventsPublished)-before)
+	})
+
+	t.Run("keeps_events_when_publish_fails", func(t *testing.T) {
+		outbox := newOutbox(3)
+		publisher := &recordingPublisher{err: errors.New("broker unavailable")}
+		relay := service.NewOutboxRelay(outbox, publisher, logger, service.OutboxOptions{BatchSize: 10})
+		before := testutil.ToFloat64(metrics.OutboxPublishErrors)
+
+		n, err := relay.PublishBatch(context.Background())
+		require.Error(t, err)
+		assert.Zero(t, n)
+		assert.Equal(t, 3, outbox.Pending())
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.OutboxPublishErrors)-before)
+
+		// После восстановления брокера те же события публикуются повторно
+		publisher.err = nil
+		n, err = relay.PublishBatch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Zero(t, outbox.Pending())
+	})
+
+	t.Run("publish_is_bounded_by_timeout", func(t *testing.T) {
+		outbox := newOutbox(2)
+		publisher := &blockingPublisher{}
+		relay := service.NewOutboxRelay(outbox, publisher, logger, service.OutboxOptions{PublishTimeout: 20 * time.Millisecond})
+
+		start := time.Now()
+		n, err := relay.PublishBatch(context.Background())
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Zero(t, n)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, 2, outbox.Pending())
+	})
+
+	t.Run("run_drains_outbox_and_reports_lag", func(t *testing.T) {
+		outbox := newOutbox(7)
+		publisher := &recordingPublisher{}
+		relay := service.NewOutboxRelay(outbox, publisher, logger, service.OutboxOptions{
+			BatchSize: 3,
+			Interval:  10 * time.Millisecond,
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			relay.Run(ctx)
+		}()
+
+		assert.Eventually(t, func() bool { return outbox.Pending() == 0 }, time.Second, 5*time.Millisecond)
+		assert.Eventually(t, func() bool {
+			return testutil.ToFloat64(metrics.OutboxPendingEvents) == 0
+		}, time.Second, 5*time.Millisecond)
+		assert.Zero(t, testutil.ToFloat64(metrics.OutboxOldestEventAge))
+
+		cancel()
+		<-done
+		assert.Len(t, publisher.published(), 7)
+	})
+
+	t.Run("lag_gauges_show_unpublished_events", func(t *testing.T) {
+		outbox := mocks.NewMockOutbox()
+		outbox.Add("order_old", time.Now().Add(-time.Minute))
+		outbox.Add("order_new", time.Now())
+		publisher := &recordingPublisher{err: errors.New("broker unavailable")}
+		relay := service.NewOutboxRelay(outbox, publisher, logger, service.OutboxOptions{Interval: time.Hour})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			relay.Run(ctx)
+		}()
+
+		assert.Eventually(t, func() bool {
+			return testutil.ToFloat64(metrics.OutboxPendingEvents) == 2
+		}, time.Second, 5*time.Millisecond)
+		assert.GreaterOrEqual(t, testutil.ToFloat64(metrics.OutboxOldestEventAge), 60.0)
+
+		cancel()
+		<-done
+	})
+}
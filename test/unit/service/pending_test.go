@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"L0/internal/models"
+	"L0/internal/repository"
+	"L0/internal/service"
+	"L0/test/mocks"
+	"L0/test/testutils"
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingStatusSweeper(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	pendingEvent := func(eventID, orderUID string) repository.StatusEvent {
+		return repository.StatusEvent{EventID: eventID, OrderUID: orderUID, Status: models.StatusPaid, OccurredAt: time.Now()}
+	}
+	createOrder := func(t *testing.T, repo *mocks.MockRepository, orderUID string) {
+		order := testutils.MinimalOrderFixture(orderUID)
+		order.Status = string(models.StatusCreated)
+		_, err := repo.CreateOrder(context.Background(), order)
+		require.NoError(t, err)
+	}
+
+	t.Run("applies_events_of_stored_orders", func(t *testing.T) {
+		repo := mocks.NewMockRepository()
+		orderService := service.NewOrderService(repo, mocks.NewMockCache(), logger)
+		sweeper := service.NewPendingStatusSweeper(repo, orderService, logger, service.PendingSweepOptions{TTL: time.Hour})
+
+		require.NoError(t, repo.SavePendingStatusEvent(context.Background(), pendingEvent("e1", "stranded")))
+		require.NoError(t, repo.SavePendingStatusEvent(context.Background(), pendingEvent("e2", "not_arrived")))
+		// Заказ сохранён, но отложенное событие применить не удалось
+		createOrder(t, repo, "stranded")
+
+		applied, err := sweeper.Sweep(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, applied)
+
+		order, err := repo.GetOrderByUID(context.Background(), "stranded")
+		require.NoError(t, err)
+		assert.Equal(t, string(models.StatusPaid), order.Status)
+		assert.Equal(t, 1, repo.PendingCount(), "event of a missing order must wait")
+	})
+
+	t.Run("purges_expired_events", func(t *testing.T) {
+		repo := mocks.NewMockRepository()
+		orders := mocks.NewMockOrderService()
+		sweeper := service.NewPendingStatusSweeper(repo, orders, logger, service.PendingSweepOptions{TTL: time.Hour})
+
+		require.NoError(t, repo.SavePendingStatusEvent(context.Background(), pendingEvent("old", "never_arrived")))
+		require.NoError(t, repo.SavePendingStatusEvent(context.Background(), pendingEvent("fresh", "late")))
+		repo.SetPendingReceivedAt("old", time.Now().Add(-2*time.Hour))
+
+		applied, err := sweeper.Sweep(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, applied)
+		assert.Equal(t, 1, repo.PendingCount())
+		assert.Empty(t, orders.PendingApplied)
+	})
+
+	t.Run("failed_order_does_not_stop_sweep", func(t *testing.T) {
+		repo := mocks.NewMockRepository()
+		orders := mocks.NewMockOrderService()
+		orders.ShouldFail = true
+		orders.FailError = assert.AnError
+		sweeper := service.NewPendingStatusSweeper(repo, orders, logger, service.PendingSweepOptions{})
+
+		createOrder(t, repo, "a")
+		createOrder(t, repo, "b")
+		require.NoError(t, repo.SavePendingStatusEvent(context.Background(), pendingEvent("e1", "a")))
+		require.NoError(t, repo.SavePendingStatusEvent(context.Background(), pendingEvent("e2", "b")))
+
+		applied, err := sweeper.Sweep(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, applied)
+		assert.Equal(t, 2, repo.PendingCount(), "events stay for the next sweep")
+	})
+}
@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"L0/internal/service"
+	"L0/test/mocks"
+	"L0/test/testutils"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheSnapshotter(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	opts := service.SnapshotOptions{Path: path, Interval: time.Hour, MaxAge: time.Hour}
+
+	repo := mocks.NewMockRepository()
+	for _, uid := range []string{"old_1", "old_2", "changed"} {
+		_, err := repo.CreateOrder(context.Background(), testutils.MinimalOrderFixture(uid))
+		require.NoError(t, err)
+		repo.SetUpdatedAt(uid, time.Now().Add(-time.Hour))
+	}
+
+	source := mocks.NewMockCache()
+	for _, uid := range []string{"old_1", "old_2"} {
+		source.Store[uid] = testutils.MinimalOrderFixture(uid)
+	}
+	require.NoError(t, service.NewCacheSnapshotter(source, nil, logger, opts).Save())
+
+	t.Run("restore_and_reconcile", func(t *testing.T) {
+		// После снимка заказ "changed" изменён в БД
+		repo.SetUpdatedAt("changed", time.Now())
+
+		restored := mocks.NewMockCache()
+		warmer := service.NewCacheWarmer(repo, restored, logger, service.WarmupOptions{})
+		snapshotter := service.NewCacheSnapshotter(restored, warmer, logger, opts)
+
+		takenAt, ok := snapshotter.Restore()
+		require.True(t, ok)
+		assert.Equal(t, []string{"old_1", "old_2"}, restored.Keys())
+
+		loaded, err := snapshotter.Reconcile(context.Background(), takenAt)
+		require.NoError(t, err)
+		assert.Equal(t, 1, loaded)
+		assert.Equal(t, []string{"changed", "old_1", "old_2"}, restored.Keys())
+	})
+
+	t.Run("restore_keeps_lru_recency", func(t *testing.T) {
+		lruPath := filepath.Join(t.TempDir(), "lru.snapshot")
+		lruOpts := service.SnapshotOptions{Path: lruPath, Interval: time.Hour}
+
+		source := cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{})
+		for _, uid := range []string{"cold", "warm", "hot"} {
+			source.Set(uid, testutils.MinimalOrderFixture(uid), cache.NoExpiration)
+		}
+		require.NoError(t, service.NewCacheSnapshotter(source, nil, logger, lruOpts).Save())
+
+		restored := cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{})
+		_, ok := service.NewCacheSnapshotter(restored, nil, logger, lruOpts).Restore()
+		require.True(t, ok)
+		assert.Equal(t, []string{"hot", "warm", "cold"}, restored.Keys())
+	})
+
+	t.Run("corrupted_snapshot_is_discarded", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[len(data)-5] ^= 0xff
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		restored := mocks.NewMockCache()
+		_, ok := service.NewCacheSnapshotter(restored, nil, logger, opts).Restore()
+
+		assert.False(t, ok)
+		assert.Zero(t, restored.Len())
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("run_saves_on_shutdown", func(t *testing.T) {
+		source := mocks.NewMockCache()
+		source.Store["final"] = testutils.MinimalOrderFixture("final")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		service.NewCacheSnapshotter(source, nil, logger, opts).Run(ctx)
+
+		snapshot, err := cache.ReadSnapshot[*dto.OrderDTO](path, 0, time.Now())
+		require.NoError(t, err)
+		require.Len(t, snapshot.Entries, 1)
+		assert.Equal(t, "final", snapshot.Entries[0].Key)
+	})
+}
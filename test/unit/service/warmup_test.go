@@ -0,0 +1,115 @@
+package service_test
+
+import (
+	"L0/internal/cache"
+	"L0/internal/kafka/dto"
+	"L0/internal/service"
+	"L0/test/mocks"
+	"L0/test/testutils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheWarmer_Run(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	newRepo := func(t *testing.T, n int) *mocks.MockRepository {
+		repo := mocks.NewMockRepository()
+		for i := 0; i < n; i++ {
+			_, err := repo.CreateOrder(context.Background(), testutils.MinimalOrderFixture(fmt.Sprintf("order_%02d", i)))
+			require.NoError(t, err)
+		}
+		return repo
+	}
+
+	t.Run("loads_all_orders_in_batches", func(t *testing.T) {
+		repo := newRepo(t, 25)
+		cache := mocks.NewMockCache()
+
+		warmer := service.NewCacheWarmer(repo, cache, logger, service.WarmupOptions{BatchSize: 10})
+		loaded, err := warmer.Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 25, loaded)
+		assert.Len(t, cache.Store, 25)
+		assert.Equal(t, 3, repo.CallsListOrders)
+		assert.Equal(t, 0, repo.CallsGetAllOrders, "warm-up must not load the whole table at once")
+	})
+
+	t.Run("limited_to_max_orders", func(t *testing.T) {
+		repo := newRepo(t, 25)
+		cache := mocks.NewMockCache()
+
+		warmer := service.NewCacheWarmer(repo, cache, logger, service.WarmupOptions{BatchSize: 10, MaxOrders: 15})
+		loaded, err := warmer.Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 15, loaded)
+		assert.Len(t, cache.Store, 15)
+		assert.Equal(t, 2, repo.CallsListOrders)
+	})
+
+	t.Run("limited_to_window", func(t *testing.T) {
+		repo := newRepo(t, 3)
+		old := testutils.MinimalOrderFixture("old_order")
+		old.DateCreated = time.Now().Add(-48 * time.Hour).Format(time.RFC3339)
+		_, err := repo.CreateOrder(context.Background(), old)
+		require.NoError(t, err)
+		cache := mocks.NewMockCache()
+
+		warmer := service.NewCacheWarmer(repo, cache, logger, service.WarmupOptions{Window: 24 * time.Hour})
+		loaded, err := warmer.Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, loaded)
+		assert.NotContains(t, cache.Store, "old_order")
+	})
+
+	t.Run("bounded_cache_keeps_newest_orders", func(t *testing.T) {
+		repo := newRepo(t, 25)
+		lru := cache.NewLRU(cache.LRUOptions[string, *dto.OrderDTO]{MaxEntries: 10})
+
+		warmer := service.NewCacheWarmer(repo, lru, logger, service.WarmupOptions{BatchSize: 4})
+		loaded, err := warmer.Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 10, loaded)
+		assert.Equal(t, 3, repo.CallsListOrders, "warm-up must stop once the cache is full")
+		// Мок отдаёт заказы по order_uid: order_00 играет роль самого свежего
+		want := make([]string, 0, 10)
+		for i := 0; i < 10; i++ {
+			want = append(want, fmt.Sprintf("order_%02d", i))
+		}
+		assert.Equal(t, want, lru.Keys(), "newest orders must stay and be evicted last")
+		assert.Zero(t, lru.Stats().Evictions)
+	})
+
+	t.Run("empty_database", func(t *testing.T) {
+		repo := newRepo(t, 0)
+		cache := mocks.NewMockCache()
+
+		loaded, err := service.NewCacheWarmer(repo, cache, logger, service.WarmupOptions{}).Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Zero(t, loaded)
+		assert.Empty(t, cache.Store)
+	})
+
+	t.Run("repo_error", func(t *testing.T) {
+		repo := newRepo(t, 5)
+		repo.ShouldFail = true
+		repo.FailError = errors.New("database error")
+
+		_, err := service.NewCacheWarmer(repo, mocks.NewMockCache(), logger, service.WarmupOptions{}).Run(context.Background())
+
+		assert.ErrorIs(t, err, repo.FailError)
+	})
+}
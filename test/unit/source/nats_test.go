@@ -0,0 +1,85 @@
+package source_test
+
+import (
+	natssource "L0/internal/source/nats"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runJetStream запускает встроенный сервер NATS с потоком ORDERS на subject orders
+func runJetStream(t *testing.T) (*nats.Conn, jetstream.JetStream) {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir()})
+	require.NoError(t, err)
+	srv.Start()
+	t.Cleanup(srv.Shutdown)
+	require.True(t, srv.ReadyForConnections(5*time.Second))
+
+	conn, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+	_, err = js.CreateStream(context.Background(), jetstream.StreamConfig{Name: "ORDERS", Subjects: []string{"orders"}})
+	require.NoError(t, err)
+	return conn, js
+}
+
+func TestNATSReader(t *testing.T) {
+	conn, js := runJetStream(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, value := range []string{`{"n":1}`, `{"n":2}`, `{"n":3}`} {
+		_, err := js.Publish(ctx, "orders", []byte(value))
+		require.NoError(t, err)
+	}
+
+	opts := natssource.Options{
+		URL:           conn.ConnectedUrl(),
+		Stream:        "ORDERS",
+		Subject:       "orders",
+		Durable:       "l0",
+		AckWait:       time.Minute,
+		MaxAckPending: 2,
+	}
+	r, err := natssource.NewReader(ctx, opts)
+	require.NoError(t, err)
+
+	info, err := js.Consumer(ctx, "ORDERS", "l0")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, info.CachedInfo().Config.AckWait)
+	assert.Equal(t, 2, info.CachedInfo().Config.MaxAckPending)
+
+	first, err := r.Fetch(ctx)
+	require.NoError(t, err)
+	second, err := r.Fetch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"n":1}`, `{"n":2}`}, []string{string(first.Value), string(second.Value)})
+
+	// Подтверждение второго сообщения подтверждает и первое
+	require.NoError(t, r.Commit(ctx, second))
+	third, err := r.Fetch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, `{"n":3}`, string(third.Value))
+	require.NoError(t, r.Close())
+
+	// Неподтверждённое сообщение достаётся следующему читателю того же durable-консьюмера
+	opts.AckWait = 100 * time.Millisecond
+	r, err = natssource.NewReader(ctx, opts)
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+
+	redelivered, err := r.Fetch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, `{"n":3}`, string(redelivered.Value))
+	assert.Equal(t, third.Offset, redelivered.Offset)
+}
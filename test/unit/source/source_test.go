@@ -0,0 +1,134 @@
+package source_test
+
+import (
+	"L0/internal/source"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fetchValues(t *testing.T, r source.Reader, n int) []string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	values := make([]string, 0, n)
+	for len(values) < n {
+		m, err := r.Fetch(ctx)
+		require.NoError(t, err)
+		values = append(values, string(m.Value))
+	}
+	return values
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestStreamReader(t *testing.T) {
+	r := source.NewStreamReader(strings.NewReader("{\"a\":1}\n\n  {\"b\":2}  \n{\"c\":3}"), "stdin")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var offsets []int64
+	var values []string
+	for i := 0; i < 3; i++ {
+		m, err := r.Fetch(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "stdin", m.Topic)
+		offsets = append(offsets, m.Offset)
+		values = append(values, string(m.Value))
+	}
+	assert.Equal(t, []string{`{"a":1}`, `{"b":2}`, `{"c":3}`}, values)
+	assert.Equal(t, []int64{1, 2, 3}, offsets)
+
+	// Конец потока: Fetch ждёт остановки
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer waitCancel()
+	_, err := r.Fetch(waitCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestFileReader(t *testing.T) {
+	t.Run("reads_directory_in_name_order", func(t *testing.T) {
+		dir := t.TempDir()
+		appendFile(t, filepath.Join(dir, "b.ndjson"), "{\"n\":3}\n")
+		appendFile(t, filepath.Join(dir, "a.jsonl"), "{\"n\":1}\n{\"n\":2}\n")
+		appendFile(t, filepath.Join(dir, "notes.txt"), "ignored\n")
+
+		r, err := source.NewFileReader(dir, "orders", 10*time.Millisecond)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{`{"n":1}`, `{"n":2}`, `{"n":3}`}, fetchValues(t, r, 3))
+	})
+
+	t.Run("follows_appended_lines_and_new_files", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "orders.ndjson")
+		appendFile(t, path, "{\"n\":1}\n{\"n\":2")
+
+		r, err := source.NewFileReader(dir, "orders", 10*time.Millisecond)
+		require.NoError(t, err)
+
+		m, err := r.Fetch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, `{"n":1}`, string(m.Value))
+		headers := map[string]string{}
+		for _, h := range m.Headers {
+			headers[h.Key] = string(h.Value)
+		}
+		assert.Equal(t, path, headers[source.HeaderSourceFile])
+		assert.Equal(t, "1", headers[source.HeaderSourceLine])
+
+		// Незавершённая строка не отдаётся, пока её дописывают
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			appendFile(t, path, "}\n")
+			appendFile(t, filepath.Join(dir, "orders2.ndjson"), "{\"n\":3}\n")
+		}()
+		assert.Equal(t, []string{`{"n":2}`, `{"n":3}`}, fetchValues(t, r, 2))
+	})
+
+	t.Run("emits_settled_last_line_without_newline", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "orders.ndjson")
+		appendFile(t, path, "{\"n\":1}\n{\"n\":2}")
+
+		r, err := source.NewFileReader(path, "orders", 10*time.Millisecond)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{`{"n":1}`, `{"n":2}`}, fetchValues(t, r, 2))
+
+		// Перевод строки, дописанный позже, не порождает повторного сообщения
+		appendFile(t, path, "\n{\"n\":3}\n")
+		assert.Equal(t, []string{`{"n":3}`}, fetchValues(t, r, 1))
+	})
+
+	t.Run("rereads_truncated_file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "orders.ndjson")
+		appendFile(t, path, "{\"n\":1}\n{\"n\":2}\n")
+
+		r, err := source.NewFileReader(path, "orders", 10*time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`{"n":1}`, `{"n":2}`}, fetchValues(t, r, 2))
+
+		require.NoError(t, os.WriteFile(path, []byte("{\"n\":9}\n"), 0o644))
+		assert.Equal(t, []string{`{"n":9}`}, fetchValues(t, r, 1))
+	})
+
+	t.Run("missing_path", func(t *testing.T) {
+		_, err := source.NewFileReader(filepath.Join(t.TempDir(), "missing"), "orders", time.Second)
+		assert.Error(t, err)
+	})
+}